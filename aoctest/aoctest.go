@@ -0,0 +1,143 @@
+// Package aoctest contains helpers for testing the Solve functions of the
+// days.
+//
+// The puzzle input of a day stays in dayNN/input.txt, where the runner reads
+// it, and only its answers are stored in dayNN/testdata/input.answers.
+// Additional inputs for the golden tests can be put into the testdata
+// directory next to their answers, e.g. testdata/large.txt and
+// testdata/large.answers.
+package aoctest
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the answers of the golden tests to testdata instead of checking them")
+
+type SolveFunc func(filename string) (partOne, partTwo string)
+
+// Golden checks the answers for all inputs that have a golden file. The file
+// testdata/NAME.answers contains the expected answer to part one in the first
+// line and to part two in the second line. The input is read from
+// testdata/NAME.txt or, if that does not exist, from NAME.txt in the package
+// directory, so that the answers for the puzzle input are stored in
+// testdata/input.answers. An empty line means that there is no answer.
+//
+// Run the tests with -update to write the current answers to the golden
+// files. If there are no golden files yet, -update creates
+// testdata/input.answers, otherwise the test is skipped.
+func Golden(t *testing.T, solve SolveFunc) {
+	filenames, err := filepath.Glob(filepath.Join("testdata", "*.answers"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(filenames) == 0 {
+		// A new day starts without answers, which are created from the
+		// puzzle input by the first run with -update.
+		if !*update {
+			t.Skip("no golden files in testdata, run with -update to create testdata/input.answers")
+		}
+		if err := os.MkdirAll("testdata", 0755); err != nil {
+			t.Fatal(err)
+		}
+		filenames = []string{filepath.Join("testdata", "input.answers")}
+	}
+
+	for _, filename := range filenames {
+		name := strings.TrimSuffix(filepath.Base(filename), ".answers")
+		t.Run(name, func(t *testing.T) {
+			input := filepath.Join("testdata", name+".txt")
+			if _, err := os.Stat(input); os.IsNotExist(err) {
+				input = name + ".txt"
+			}
+
+			partOne, partTwo := solve(input)
+
+			if *update {
+				data := partOne + "\n" + partTwo + "\n"
+				if err := ioutil.WriteFile(filename, []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+
+			data, err := ioutil.ReadFile(filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+			for len(lines) < 2 {
+				lines = append(lines, "")
+			}
+
+			if partOne != lines[0] {
+				t.Errorf("part one: got %q, want %q", partOne, lines[0])
+			}
+			if partTwo != lines[1] {
+				t.Errorf("part two: got %q, want %q", partTwo, lines[1])
+			}
+		})
+	}
+}
+
+// Solve writes the input to a temporary file and solves it, for testing the
+// examples from the puzzle statements.
+func Solve(t *testing.T, solve SolveFunc, input string) (partOne, partTwo string) {
+	t.Helper()
+
+	filename := TempFile(t, input)
+	defer os.Remove(filename)
+
+	return solve(filename)
+}
+
+// TempFile writes the input to a temporary file, for testing functions that
+// read files. The caller must remove the file.
+func TempFile(t *testing.T, input string) string {
+	t.Helper()
+
+	file, err := ioutil.TempFile("", "aoctest-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = file.WriteString(input)
+	if err == nil {
+		err = file.Close()
+	}
+	if err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		t.Fatal(err)
+	}
+
+	return file.Name()
+}
+
+// Benchmark solves the puzzle input of the day b.N times. Both parts are
+// included, because Solve computes them together.
+func Benchmark(b *testing.B, solve SolveFunc) {
+	if _, err := os.Stat("input.txt"); err != nil {
+		b.Skip(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		solve("input.txt")
+	}
+}
+
+// Check reports an error if the answer is not the expected one. An empty
+// expected answer is not checked, for examples that only apply to one part.
+func Check(t *testing.T, part, got, want string) {
+	t.Helper()
+	if want != "" && got != want {
+		t.Errorf("%s: got %q, want %q", part, got, want)
+	}
+}
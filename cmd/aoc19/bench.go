@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"greenlightning.eu/aoc19/input"
+)
+
+// A Report contains the results of one invocation of the bench command. It is
+// written to the file given by -json, so that it can later be passed to
+// -baseline to compare the runs before and after a change.
+type Report struct {
+	GoVersion string        `json:"go_version"`
+	Date      time.Time     `json:"date"`
+	Runs      int           `json:"runs"`
+	Results   []BenchResult `json:"results"`
+}
+
+// The times are in nanoseconds, the allocations are averaged over all runs.
+type BenchResult struct {
+	Day    int             `json:"day"`
+	Times  []time.Duration `json:"times_ns"`
+	Median time.Duration   `json:"median_ns"`
+	P90    time.Duration   `json:"p90_ns"`
+	Min    time.Duration   `json:"min_ns"`
+	Max    time.Duration   `json:"max_ns"`
+	Allocs uint64          `json:"allocs"`
+	Bytes  uint64          `json:"bytes"`
+	Error  string          `json:"error,omitempty"`
+}
+
+func bench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ContinueOnError)
+	dayFlag := flags.Int("day", 0, "only benchmark `day` instead of all days")
+	runsFlag := flags.Int("n", 5, "run each day `n` times")
+	source := addInputFlags(flags)
+	jsonFlag := flags.String("json", "", "write the results to `file`")
+	baselineFlag := flags.String("baseline", "", "compare the median times to the results in `file`")
+	profiling := addProfilingFlags(flags)
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if *runsFlag <= 0 {
+		return fmt.Errorf("-n must be positive")
+	}
+	finishInputFlags(source)
+
+	var numbers []int
+	if *dayFlag != 0 {
+		if _, ok := days[*dayFlag]; !ok {
+			return fmt.Errorf("no solution for day %d", *dayFlag)
+		}
+		numbers = append(numbers, *dayFlag)
+	} else {
+		for number := range days {
+			numbers = append(numbers, number)
+		}
+		sort.Ints(numbers)
+	}
+
+	baseline := make(map[int]BenchResult)
+	if *baselineFlag != "" {
+		data, err := ioutil.ReadFile(*baselineFlag)
+		if err != nil {
+			return err
+		}
+		var report Report
+		if err := json.Unmarshal(data, &report); err != nil {
+			return fmt.Errorf("%s: %v", *baselineFlag, err)
+		}
+		for _, result := range report.Results {
+			baseline[result.Day] = result
+		}
+	}
+
+	report := Report{
+		GoVersion: runtime.Version(),
+		Date:      time.Now().UTC(),
+		Runs:      *runsFlag,
+	}
+
+	const format = "%-4v %10v %10v %10v %10v %12v %12v %v\n"
+	fmt.Printf(format, "Day", "Median", "P90", "Min", "Max", "Allocs", "Bytes", "Change")
+
+	round := func(d time.Duration) time.Duration {
+		return d.Round(time.Microsecond)
+	}
+
+	failed := 0
+	err := profiling.Run(func() error {
+		for _, number := range numbers {
+			result := benchDay(source, number, *runsFlag)
+			report.Results = append(report.Results, result)
+
+			if result.Error != "" {
+				failed++
+				fmt.Printf("%-4v error: %v\n", number, result.Error)
+				continue
+			}
+
+			change := ""
+			if old, ok := baseline[number]; ok && old.Error == "" && old.Median != 0 {
+				change = fmt.Sprintf("%+.1f%%", 100*(float64(result.Median)/float64(old.Median)-1))
+			}
+
+			fmt.Printf(format, number, round(result.Median), round(result.P90), round(result.Min), round(result.Max), result.Allocs, result.Bytes, change)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if *jsonFlag != "" {
+		data, err := json.MarshalIndent(report, "", "\t")
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(*jsonFlag, append(data, '\n'), 0644); err != nil {
+			return err
+		}
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(numbers))
+	}
+	return nil
+}
+
+// Runs the day the given number of times and collects the statistics. The
+// allocations are measured with runtime.ReadMemStats, which includes any
+// goroutines that are still running from previous runs, so they are only an
+// approximation for the days that use the intcode emulator concurrently.
+func benchDay(source *input.Source, number, runs int) BenchResult {
+	result := BenchResult{Day: number}
+
+	filename, cleanup, err := source.Resolve(number)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer cleanup()
+
+	var before, after runtime.MemStats
+	for i := 0; i < runs; i++ {
+		runtime.GC()
+		runtime.ReadMemStats(&before)
+
+		start := time.Now()
+		_, _, err := solve(days[number], filename)
+		elapsed := time.Since(start)
+
+		runtime.ReadMemStats(&after)
+
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+
+		result.Times = append(result.Times, elapsed)
+		result.Allocs += after.Mallocs - before.Mallocs
+		result.Bytes += after.TotalAlloc - before.TotalAlloc
+	}
+
+	result.Allocs /= uint64(runs)
+	result.Bytes /= uint64(runs)
+
+	sorted := make([]time.Duration, len(result.Times))
+	copy(sorted, result.Times)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	result.Median = percentile(sorted, 50)
+	result.P90 = percentile(sorted, 90)
+	result.Min = sorted[0]
+	result.Max = sorted[len(sorted)-1]
+
+	return result
+}
+
+// Returns the p-th percentile of the sorted times using the nearest-rank
+// method.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
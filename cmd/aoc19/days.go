@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+
+	"greenlightning.eu/aoc19/day01"
+	"greenlightning.eu/aoc19/day02"
+	"greenlightning.eu/aoc19/day03"
+	"greenlightning.eu/aoc19/day04"
+	"greenlightning.eu/aoc19/day05"
+	"greenlightning.eu/aoc19/day06"
+	"greenlightning.eu/aoc19/day07"
+	"greenlightning.eu/aoc19/day08"
+	"greenlightning.eu/aoc19/day09"
+	"greenlightning.eu/aoc19/day10"
+	"greenlightning.eu/aoc19/day11"
+	"greenlightning.eu/aoc19/day12"
+	"greenlightning.eu/aoc19/day13"
+	"greenlightning.eu/aoc19/day14"
+	"greenlightning.eu/aoc19/day15"
+	"greenlightning.eu/aoc19/day16"
+	"greenlightning.eu/aoc19/day17"
+	"greenlightning.eu/aoc19/day18"
+	"greenlightning.eu/aoc19/day19"
+	"greenlightning.eu/aoc19/day20"
+	"greenlightning.eu/aoc19/day21"
+	"greenlightning.eu/aoc19/day22"
+	"greenlightning.eu/aoc19/day23"
+	"greenlightning.eu/aoc19/day24"
+	"greenlightning.eu/aoc19/day25"
+)
+
+// A Day is the solution of one puzzle. Every day package provides a Solve
+// function, which reads the input from the file and returns the answers to
+// both parts (or an empty string if a part has no answer, e.g. because the
+// flags select a different mode). Days that have options also provide Flags,
+// which are parsed by the runner.
+//
+// The days are registered by "aoc19 new", which rewrites this file.
+type Day struct {
+	Solve func(filename string) (partOne, partTwo string)
+	Flags *flag.FlagSet
+}
+
+var days = map[int]Day{
+	1:  {Solve: day01.Solve},
+	2:  {Solve: day02.Solve},
+	3:  {Solve: day03.Solve},
+	4:  {Solve: day04.Solve},
+	5:  {Solve: day05.Solve},
+	6:  {Solve: day06.Solve},
+	7:  {Solve: day07.Solve},
+	8:  {Solve: day08.Solve, Flags: day08.Flags},
+	9:  {Solve: day09.Solve},
+	10: {Solve: day10.Solve, Flags: day10.Flags},
+	11: {Solve: day11.Solve, Flags: day11.Flags},
+	12: {Solve: day12.Solve},
+	13: {Solve: day13.Solve, Flags: day13.Flags},
+	14: {Solve: day14.Solve},
+	15: {Solve: day15.Solve, Flags: day15.Flags},
+	16: {Solve: day16.Solve},
+	17: {Solve: day17.Solve, Flags: day17.Flags},
+	18: {Solve: day18.Solve},
+	19: {Solve: day19.Solve, Flags: day19.Flags},
+	20: {Solve: day20.Solve},
+	21: {Solve: day21.Solve},
+	22: {Solve: day22.Solve},
+	23: {Solve: day23.Solve, Flags: day23.Flags},
+	24: {Solve: day24.Solve, Flags: day24.Flags},
+	25: {Solve: day25.Solve, Flags: day25.Flags},
+}
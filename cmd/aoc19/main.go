@@ -0,0 +1,255 @@
+// Command aoc19 runs the solutions of all days from a single binary.
+//
+// Usage:
+//
+//	aoc19 run -day 12 [-input file] [flags of the day]
+//	aoc19 run -all
+//	aoc19 bench [-day 12] [-n runs] [-json file] [-baseline file]
+//	aoc19 new -day 12 [-vectors] [-priority] [-permutations] [-math]
+//
+// The run and bench commands accept -cpuprofile, -memprofile, -blockprofile
+// and -exectrace to profile the days while they run. The new command creates the
+// directory of a day from the template files and registers it in days.go.
+//
+// By default, the input of each day is read from dayNN/input.txt relative to
+// the directory given by -dir, which is the current directory, so the command
+// is usually run from the root of the repository.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"greenlightning.eu/aoc19/input"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "run":
+		err = run(os.Args[2:])
+	case "bench":
+		err = bench(os.Args[2:])
+	case "new":
+		err = newDay(os.Args[2:])
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "aoc19: unknown command %q\n", os.Args[1])
+		usage()
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "aoc19: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage:")
+	fmt.Fprintln(os.Stderr, "  aoc19 run -day N [-input file] [flags of the day]")
+	fmt.Fprintln(os.Stderr, "  aoc19 run -all")
+	fmt.Fprintln(os.Stderr, "  aoc19 bench [-day N] [-n runs] [-json file] [-baseline file]")
+	fmt.Fprintln(os.Stderr, "  aoc19 new -day N [-vectors] [-priority] [-permutations] [-math]")
+	fmt.Fprintln(os.Stderr, "Run 'aoc19 run -day N -h' to see the flags of a day.")
+	os.Exit(2)
+}
+
+func run(args []string) error {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	dayFlag := flags.Int("day", 0, "run the solution of `day`")
+	allFlag := flags.Bool("all", false, "run the solutions of all days")
+	inputFlag := flags.String("input", "", "read the input from `file` (- for stdin) instead of dayNN/input.txt")
+	source := addInputFlags(flags)
+	profiling := addProfilingFlags(flags)
+
+	// The flags of the day are parsed together with the flags of the run
+	// command, so they must be added before parsing.
+	if number, ok := findDay(args); ok {
+		if err := addDayFlags(flags, number); err != nil {
+			return err
+		}
+	}
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	finishInputFlags(source)
+
+	if *allFlag {
+		if *dayFlag != 0 || *inputFlag != "" {
+			return fmt.Errorf("-all cannot be combined with -day or -input")
+		}
+		return profiling.Run(func() error {
+			return runAll(source)
+		})
+	}
+
+	day, ok := days[*dayFlag]
+	if !ok {
+		return fmt.Errorf("no solution for day %d", *dayFlag)
+	}
+
+	source.Path = *inputFlag
+	filename, cleanup, err := source.Resolve(*dayFlag)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	return profiling.Run(func() error {
+		return runDay(day, *dayFlag, filename)
+	})
+}
+
+// Adds the flags of the day to the flags of the run command. A flag of the
+// day must not have the same name as a flag of the command, because the flag
+// package panics if a flag is defined twice.
+func addDayFlags(flags *flag.FlagSet, number int) error {
+	day, ok := days[number]
+	if !ok || day.Flags == nil {
+		return nil
+	}
+
+	var conflicts []string
+	day.Flags.VisitAll(func(f *flag.Flag) {
+		if flags.Lookup(f.Name) != nil {
+			conflicts = append(conflicts, "-"+f.Name)
+			return
+		}
+		flags.Var(f.Value, f.Name, f.Usage)
+	})
+	if len(conflicts) != 0 {
+		return fmt.Errorf("day %d: flags %s conflict with the flags of the run command", number, strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+func runDay(day Day, number int, filename string) error {
+	partOne, partTwo, err := solve(day, filename)
+	if err != nil {
+		return fmt.Errorf("day %d: %v", number, err)
+	}
+
+	if partOne != "" {
+		fmt.Println("--- Part One ---")
+		fmt.Println(partOne)
+	}
+	if partTwo != "" {
+		fmt.Println("--- Part Two ---")
+		fmt.Println(partTwo)
+	}
+
+	return nil
+}
+
+// Prints a table with the answers of all days and the time each day took.
+func runAll(source *input.Source) error {
+	var numbers []int
+	for number := range days {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	// Print each line as soon as the day is done, instead of aligning the
+	// columns to the longest answer.
+	const format = "%-4v %-16v %-16v %v\n"
+	fmt.Printf(format, "Day", "Part One", "Part Two", "Time")
+
+	failed := 0
+	for _, number := range numbers {
+		filename, cleanup, err := source.Resolve(number)
+		if err != nil {
+			failed++
+			fmt.Printf(format, number, "error: "+err.Error(), "", "")
+			continue
+		}
+
+		start := time.Now()
+		partOne, partTwo, err := solve(days[number], filename)
+		elapsed := time.Since(start)
+		cleanup()
+
+		if err != nil {
+			failed++
+			partOne, partTwo = "error: "+err.Error(), ""
+		}
+		fmt.Printf(format, number, partOne, partTwo, elapsed.Round(time.Millisecond))
+	}
+
+	if failed != 0 {
+		return fmt.Errorf("%d of %d days failed", failed, len(numbers))
+	}
+	return nil
+}
+
+// Adds the flags that select where the inputs come from. The inputs are
+// cached in the day directories, so by default the input of each day is read
+// from dayNN/input.txt. Missing inputs are downloaded if a session is set.
+func addInputFlags(flags *flag.FlagSet) *input.Source {
+	source := &input.Source{Retries: 3, RetryDelay: time.Second}
+	flags.StringVar(&source.CacheDir, "dir", ".", "look for the day directories in `directory` and cache downloaded inputs there")
+	flags.StringVar(&source.Session, "session", "", "download missing inputs using the session `cookie` (default $"+input.SessionEnv+")")
+	flags.StringVar(&source.BaseURL, "url", input.DefaultBaseURL, "download inputs from the server at `url`")
+	return source
+}
+
+// Applies the default session after parsing, so that it is not shown in the
+// usage.
+func finishInputFlags(source *input.Source) {
+	if source.Session == "" {
+		source.Session = os.Getenv(input.SessionEnv)
+	}
+}
+
+// Calls the solution and turns a panic (e.g. from check) into an error, so
+// that one failing day does not stop the others.
+func solve(day Day, filename string) (partOne, partTwo string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	partOne, partTwo = day.Solve(filename)
+	return partOne, partTwo, nil
+}
+
+// Returns the value of the -day flag, before the flags are parsed.
+func findDay(args []string) (int, bool) {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+
+		name := strings.TrimLeft(arg, "-")
+		if name == arg {
+			continue
+		}
+
+		if strings.HasPrefix(name, "day=") {
+			number, err := strconv.Atoi(strings.TrimPrefix(name, "day="))
+			return number, err == nil
+		}
+		if name == "day" && i+1 < len(args) {
+			number, err := strconv.Atoi(args[i+1])
+			return number, err == nil
+		}
+	}
+	return 0, false
+}
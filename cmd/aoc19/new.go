@@ -0,0 +1,370 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A Helper is a part of the template code that can be copied into a new day.
+type Helper struct {
+	// The template file in the root of the repository and the file in the
+	// day directory.
+	Template, Output string
+
+	// Selects the declarations that are copied. Methods belong to their
+	// receiver type. If Names is nil, all declarations except Exclude are
+	// copied.
+	Names, Exclude []string
+}
+
+var (
+	solutionHelper     = Helper{Template: "template.go", Output: "main.go"}
+	vectorHelper       = Helper{Template: "template_vector.go", Output: "vector.go"}
+	priorityHelper     = Helper{Template: "template_priority.go", Output: "priority.go", Exclude: []string{"main"}}
+	permutationsHelper = Helper{Template: "template_algorithm.go", Output: "permutations.go", Names: []string{"allPermutations"}}
+	mathHelper         = Helper{Template: "template_algorithm.go", Output: "math.go", Exclude: []string{"allPermutations"}}
+)
+
+func newDay(args []string) error {
+	flags := flag.NewFlagSet("new", flag.ContinueOnError)
+	dayFlag := flags.Int("day", 0, "create the directory for `day`")
+	dirFlag := flags.String("dir", ".", "root `directory` of the repository, which contains the templates")
+	vectorsFlag := flags.Bool("vectors", false, "include the vector and direction types from template_vector.go")
+	priorityFlag := flags.Bool("priority", false, "include the priority queue from template_priority.go")
+	permutationsFlag := flags.Bool("permutations", false, "include allPermutations from template_algorithm.go")
+	mathFlag := flags.Bool("math", false, "include pow, gcd and lcm from template_algorithm.go")
+
+	if err := flags.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+	if *dayFlag < 1 || *dayFlag > 25 {
+		return fmt.Errorf("-day must be between 1 and 25")
+	}
+
+	pkg := fmt.Sprintf("day%02d", *dayFlag)
+	dir := filepath.Join(*dirFlag, pkg)
+
+	if _, err := os.Stat(filepath.Join(dir, "main.go")); err == nil {
+		return fmt.Errorf("%s already exists", filepath.Join(dir, "main.go"))
+	}
+
+	helpers := []Helper{solutionHelper}
+	if *vectorsFlag {
+		helpers = append(helpers, vectorHelper)
+	}
+	if *priorityFlag {
+		helpers = append(helpers, priorityHelper)
+	}
+	if *permutationsFlag {
+		helpers = append(helpers, permutationsHelper)
+	}
+	if *mathFlag {
+		helpers = append(helpers, mathHelper)
+	}
+
+	// Generate everything before writing anything, so that an error in a
+	// template does not leave a half-created day behind.
+	files := make(map[string][]byte)
+	for _, helper := range helpers {
+		data, err := generate(filepath.Join(*dirFlag, helper.Template), pkg, helper)
+		if err != nil {
+			return err
+		}
+		files[helper.Output] = data
+	}
+	files["main_test.go"] = []byte(fmt.Sprintf(testTemplate, pkg))
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		filename := filepath.Join(dir, name)
+		if err := ioutil.WriteFile(filename, files[name], 0644); err != nil {
+			return err
+		}
+		fmt.Println("created", filename)
+	}
+
+	filename := filepath.Join(*dirFlag, "cmd", "aoc19", "days.go")
+	if err := writeRegistry(*dirFlag, filename); err != nil {
+		return err
+	}
+	fmt.Println("updated", filename)
+
+	return nil
+}
+
+const testTemplate = `package %s
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		input            string
+		partOne, partTwo string
+	}{
+		// {` + "`example input`" + `, "part one", "part two"},
+	}
+
+	for _, test := range tests {
+		partOne, partTwo := aoctest.Solve(t, Solve, test.input)
+		aoctest.Check(t, "part one", partOne, test.partOne)
+		aoctest.Check(t, "part two", partTwo, test.partTwo)
+	}
+}
+`
+
+// Copies the selected declarations from the template into a file of the
+// package, together with their comments and the imports they need.
+func generate(filename, pkg string, helper Helper) ([]byte, error) {
+	src, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := func(name string) bool {
+		if helper.Names != nil {
+			return contains(helper.Names, name)
+		}
+		return !contains(helper.Exclude, name)
+	}
+
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset
+	}
+
+	var body bytes.Buffer
+	used := make(map[string]bool)
+
+	// Comments between the declarations (or the imports) belong to the
+	// following declaration.
+	previousEnd := file.Name.End()
+
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.IMPORT {
+			previousEnd = decl.End()
+			continue
+		}
+
+		start := decl.Pos()
+		for _, group := range file.Comments {
+			if group.Pos() > previousEnd && group.Pos() < start {
+				start = group.Pos()
+				break
+			}
+		}
+		previousEnd = decl.End()
+
+		if !selected(declName(decl)) {
+			continue
+		}
+
+		body.Write(src[offset(start):offset(decl.End())])
+		body.WriteString("\n\n")
+
+		ast.Inspect(decl, func(node ast.Node) bool {
+			if selector, ok := node.(*ast.SelectorExpr); ok {
+				if ident, ok := selector.X.(*ast.Ident); ok && ident.Obj == nil {
+					used[ident.Name] = true
+				}
+			}
+			return true
+		})
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "package %s\n\n", pkg)
+
+	var imports []string
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, err
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		if used[name] {
+			imports = append(imports, strings.TrimSpace(string(src[offset(spec.Pos()):offset(spec.End())])))
+		}
+	}
+	switch len(imports) {
+	case 0:
+	case 1:
+		fmt.Fprintf(&out, "import %s\n\n", imports[0])
+	default:
+		fmt.Fprintf(&out, "import (\n\t%s\n)\n\n", strings.Join(imports, "\n\t"))
+	}
+
+	out.Write(body.Bytes())
+
+	data, err := format.Source(out.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return data, nil
+}
+
+// Returns the name of a function or type, or the receiver type of a method.
+func declName(decl ast.Decl) string {
+	switch decl := decl.(type) {
+	case *ast.FuncDecl:
+		if decl.Recv != nil && len(decl.Recv.List) != 0 {
+			typ := decl.Recv.List[0].Type
+			if star, ok := typ.(*ast.StarExpr); ok {
+				typ = star.X
+			}
+			if ident, ok := typ.(*ast.Ident); ok {
+				return ident.Name
+			}
+		}
+		return decl.Name.Name
+
+	case *ast.GenDecl:
+		for _, spec := range decl.Specs {
+			switch spec := spec.(type) {
+			case *ast.TypeSpec:
+				return spec.Name.Name
+			case *ast.ValueSpec:
+				return spec.Names[0].Name
+			}
+		}
+	}
+	return ""
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+var dayRegex = regexp.MustCompile(`^day(\d\d)$`)
+
+// Writes the registry of the runner for all day directories of the
+// repository. A day is registered with its flags if its package declares a
+// Flags variable.
+func writeRegistry(root, filename string) error {
+	entries, err := ioutil.ReadDir(root)
+	if err != nil {
+		return err
+	}
+
+	type entry struct {
+		number int
+		pkg    string
+		flags  bool
+	}
+
+	var registry []entry
+	for _, info := range entries {
+		match := dayRegex.FindStringSubmatch(info.Name())
+		if !info.IsDir() || match == nil {
+			continue
+		}
+
+		number, _ := strconv.Atoi(match[1])
+		e := entry{number: number, pkg: info.Name()}
+
+		fset := token.NewFileSet()
+		skipTests := func(info os.FileInfo) bool {
+			return !strings.HasSuffix(info.Name(), "_test.go")
+		}
+		pkgs, err := parser.ParseDir(fset, filepath.Join(root, info.Name()), skipTests, 0)
+		if err != nil {
+			return err
+		}
+		if len(pkgs) == 0 {
+			continue
+		}
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Files {
+				if obj := file.Scope.Lookup("Flags"); obj != nil && obj.Kind == ast.Var {
+					e.flags = true
+				}
+			}
+		}
+
+		registry = append(registry, e)
+	}
+
+	var out bytes.Buffer
+	out.WriteString("package main\n\nimport (\n\t\"flag\"\n\n")
+	for _, e := range registry {
+		fmt.Fprintf(&out, "\t\"greenlightning.eu/aoc19/%s\"\n", e.pkg)
+	}
+	out.WriteString(")\n\n")
+	out.WriteString(registryComment)
+	out.WriteString("type Day struct {\n\tSolve func(filename string) (partOne, partTwo string)\n\tFlags *flag.FlagSet\n}\n\n")
+	out.WriteString("var days = map[int]Day{\n")
+	for _, e := range registry {
+		if e.flags {
+			fmt.Fprintf(&out, "\t%d: {Solve: %s.Solve, Flags: %s.Flags},\n", e.number, e.pkg, e.pkg)
+		} else {
+			fmt.Fprintf(&out, "\t%d: {Solve: %s.Solve},\n", e.number, e.pkg)
+		}
+	}
+	out.WriteString("}\n")
+
+	data, err := format.Source(out.Bytes())
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+const registryComment = `// A Day is the solution of one puzzle. Every day package provides a Solve
+// function, which reads the input from the file and returns the answers to
+// both parts (or an empty string if a part has no answer, e.g. because the
+// flags select a different mode). Days that have options also provide Flags,
+// which are parsed by the runner.
+//
+// The days are registered by "aoc19 new", which rewrites this file.
+`
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"runtime"
+	"runtime/pprof"
+	"runtime/trace"
+	"sync"
+)
+
+// Profiling contains the profiling flags, which are shared by the commands
+// that run days. The flags have the same names as the ones of go test, except
+// for -exectrace, because day 23 has a -trace flag of its own.
+type Profiling struct {
+	cpu, mem, block, trace string
+}
+
+func addProfilingFlags(flags *flag.FlagSet) *Profiling {
+	p := &Profiling{}
+	flags.StringVar(&p.cpu, "cpuprofile", "", "write a CPU profile to `file`")
+	flags.StringVar(&p.mem, "memprofile", "", "write an allocation profile to `file` after the days have run")
+	flags.StringVar(&p.block, "blockprofile", "", "write a goroutine blocking profile to `file`")
+	flags.StringVar(&p.trace, "exectrace", "", "write an execution trace to `file`")
+	return p
+}
+
+// Start starts the requested profiles and returns a function that stops them
+// and writes the remaining files. If the process is interrupted, the profiles
+// are also written before exiting, so that a long run can be cut short.
+func (p *Profiling) Start() (stop func() error, err error) {
+	var stops []func() error
+
+	// Stop may be called both by the caller and on interrupt, but the
+	// profiles must only be stopped once.
+	var once sync.Once
+	var stopErr error
+	stop = func() error {
+		once.Do(func() {
+			for i := len(stops) - 1; i >= 0; i-- {
+				if err := stops[i](); err != nil && stopErr == nil {
+					stopErr = err
+				}
+			}
+		})
+		return stopErr
+	}
+
+	// Stops the profiles that have already been started if a later one
+	// cannot be started.
+	fail := func(err error) (func() error, error) {
+		stop()
+		return nil, err
+	}
+
+	if p.cpu != "" {
+		file, err := os.Create(p.cpu)
+		if err != nil {
+			return fail(err)
+		}
+		if err := pprof.StartCPUProfile(file); err != nil {
+			file.Close()
+			return fail(err)
+		}
+		stops = append(stops, func() error {
+			pprof.StopCPUProfile()
+			return file.Close()
+		})
+	}
+
+	if p.trace != "" {
+		file, err := os.Create(p.trace)
+		if err != nil {
+			return fail(err)
+		}
+		if err := trace.Start(file); err != nil {
+			file.Close()
+			return fail(err)
+		}
+		stops = append(stops, func() error {
+			trace.Stop()
+			return file.Close()
+		})
+	}
+
+	if p.block != "" {
+		runtime.SetBlockProfileRate(1)
+		stops = append(stops, func() error {
+			defer runtime.SetBlockProfileRate(0)
+			return writeProfile("block", p.block)
+		})
+	}
+
+	if p.mem != "" {
+		stops = append(stops, func() error {
+			// Make sure that the profile is up to date.
+			runtime.GC()
+			return writeProfile("allocs", p.mem)
+		})
+	}
+
+	if len(stops) == 0 {
+		return stop, nil
+	}
+
+	interrupt := make(chan os.Signal, 1)
+	done := make(chan bool)
+	signal.Notify(interrupt, os.Interrupt)
+
+	// The list of stop functions must be complete before the handler starts,
+	// because the handler may call stop at any time.
+	stops = append(stops, func() error {
+		signal.Stop(interrupt)
+		close(done)
+		return nil
+	})
+	go func() {
+		select {
+		case <-interrupt:
+			fmt.Fprintln(os.Stderr, "aoc19: interrupted, writing profiles")
+			if err := stop(); err != nil {
+				fmt.Fprintf(os.Stderr, "aoc19: %v\n", err)
+			}
+			os.Exit(130)
+		case <-done:
+		}
+	}()
+
+	return stop, nil
+}
+
+func writeProfile(name, filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := pprof.Lookup(name).WriteTo(file, 0); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Run calls the function with the profiles running and stops them when it
+// returns.
+func (p *Profiling) Run(f func() error) error {
+	stop, err := p.Start()
+	if err != nil {
+		return err
+	}
+
+	err = f()
+	if stopErr := stop(); err == nil {
+		err = stopErr
+	}
+	return err
+}
@@ -0,0 +1,290 @@
+// Package combinatorics enumerates permutations, combinations, subsets and
+// cartesian products lazily, without building the whole list in memory.
+//
+// The iterators work on indices from 0 to n-1, which the caller maps to its
+// own values, and they reuse the slice returned by Indices, so it is only
+// valid until the next call to Next. The usual loop is:
+//
+//	p := combinatorics.MakePermutations(len(values))
+//	for p.Next() {
+//		for _, index := range p.Indices() {
+//			use(values[index])
+//		}
+//	}
+package combinatorics
+
+import (
+	"fmt"
+	"math/bits"
+)
+
+// Permutations enumerates all orders of n indices using Heap's algorithm,
+// which swaps two elements between consecutive permutations. The first
+// permutation is the identity.
+type Permutations struct {
+	indices []int
+	counter []int
+	i       int
+	started bool
+}
+
+func MakePermutations(n int) *Permutations {
+	p := &Permutations{
+		indices: make([]int, n),
+		counter: make([]int, n),
+		i:       1,
+	}
+	for i := range p.indices {
+		p.indices[i] = i
+	}
+	return p
+}
+
+// Next advances to the next permutation and returns false if there is none.
+func (p *Permutations) Next() bool {
+	if !p.started {
+		p.started = true
+		return true
+	}
+
+	for p.i < len(p.indices) {
+		if p.counter[p.i] < p.i {
+			if p.i%2 == 0 {
+				p.swap(0, p.i)
+			} else {
+				p.swap(p.counter[p.i], p.i)
+			}
+			p.counter[p.i]++
+			p.i = 1
+			return true
+		}
+		p.counter[p.i] = 0
+		p.i++
+	}
+	return false
+}
+
+func (p *Permutations) swap(i, j int) {
+	p.indices[i], p.indices[j] = p.indices[j], p.indices[i]
+}
+
+// Indices returns the current permutation.
+func (p *Permutations) Indices() []int {
+	return p.indices
+}
+
+// Combinations enumerates all sets of k out of n indices in lexicographic
+// order. The indices of each combination are increasing.
+type Combinations struct {
+	n             int
+	indices       []int
+	started, done bool
+}
+
+func MakeCombinations(n, k int) *Combinations {
+	if k < 0 || n < 0 {
+		panic(fmt.Sprintf("combinatorics: invalid combinations of %d out of %d", k, n))
+	}
+	c := &Combinations{n: n, indices: make([]int, k)}
+	for i := range c.indices {
+		c.indices[i] = i
+	}
+	return c
+}
+
+// Next advances to the next combination and returns false if there is none.
+func (c *Combinations) Next() bool {
+	k := len(c.indices)
+	if c.done {
+		return false
+	}
+	if !c.started {
+		c.started = true
+		c.done = k > c.n
+		return !c.done
+	}
+
+	// Find the rightmost index that can be incremented and reset all
+	// indices after it to the smallest possible values.
+	for i := k - 1; i >= 0; i-- {
+		if c.indices[i] < c.n-k+i {
+			c.indices[i]++
+			for j := i + 1; j < k; j++ {
+				c.indices[j] = c.indices[j-1] + 1
+			}
+			return true
+		}
+	}
+	c.done = true
+	return false
+}
+
+// Indices returns the current combination.
+func (c *Combinations) Indices() []int {
+	return c.indices
+}
+
+// Subsets enumerates all subsets of n indices as bit masks, counting from
+// the empty set (mask 0) to the full set (mask 2^n-1).
+type Subsets struct {
+	n       int
+	mask    uint64
+	started bool
+	indices []int
+}
+
+func MakeSubsets(n int) *Subsets {
+	checkSubsetSize(n)
+	return &Subsets{n: n}
+}
+
+// Next advances to the next subset and returns false if there is none.
+func (s *Subsets) Next() bool {
+	if !s.started {
+		s.started = true
+		return true
+	}
+	if s.mask == 1<<uint(s.n)-1 {
+		return false
+	}
+	s.mask++
+	return true
+}
+
+// Mask returns the current subset, where bit i is set if index i is part of
+// the subset.
+func (s *Subsets) Mask() uint64 {
+	return s.mask
+}
+
+// Contains reports whether the index is part of the current subset.
+func (s *Subsets) Contains(index int) bool {
+	return s.mask&(1<<uint(index)) != 0
+}
+
+// Indices returns the indices of the current subset in increasing order.
+func (s *Subsets) Indices() []int {
+	s.indices = maskIndices(s.indices[:0], s.mask)
+	return s.indices
+}
+
+// GraySubsets enumerates all subsets of n indices in the order of the
+// reflected binary Gray code, so that consecutive subsets differ by exactly
+// one index. This is useful if changing the subset is expensive, like taking
+// or dropping items in a game, because each step requires only one change.
+// The first subset is the empty set.
+type GraySubsets struct {
+	n       int
+	step    uint64
+	mask    uint64
+	changed int
+	indices []int
+}
+
+func MakeGraySubsets(n int) *GraySubsets {
+	checkSubsetSize(n)
+	return &GraySubsets{n: n, step: ^uint64(0), changed: -1}
+}
+
+// Next advances to the next subset and returns false if there is none.
+func (g *GraySubsets) Next() bool {
+	if g.step == 1<<uint(g.n)-1 {
+		return false
+	}
+	g.step++
+	mask := g.step ^ g.step>>1
+
+	g.changed = -1
+	if diff := mask ^ g.mask; diff != 0 {
+		g.changed = bits.TrailingZeros64(diff)
+	}
+
+	g.mask = mask
+	return true
+}
+
+// Mask returns the current subset, where bit i is set if index i is part of
+// the subset.
+func (g *GraySubsets) Mask() uint64 {
+	return g.mask
+}
+
+// Contains reports whether the index is part of the current subset.
+func (g *GraySubsets) Contains(index int) bool {
+	return g.mask&(1<<uint(index)) != 0
+}
+
+// Changed returns the index that has been added to or removed from the
+// subset by the last call to Next, or -1 for the first subset.
+func (g *GraySubsets) Changed() int {
+	return g.changed
+}
+
+// Indices returns the indices of the current subset in increasing order.
+func (g *GraySubsets) Indices() []int {
+	g.indices = maskIndices(g.indices[:0], g.mask)
+	return g.indices
+}
+
+func checkSubsetSize(n int) {
+	if n < 0 || n >= 64 {
+		panic(fmt.Sprintf("combinatorics: cannot enumerate the subsets of %d indices", n))
+	}
+}
+
+func maskIndices(indices []int, mask uint64) []int {
+	for index := 0; mask != 0; index++ {
+		if mask&1 != 0 {
+			indices = append(indices, index)
+		}
+		mask >>= 1
+	}
+	return indices
+}
+
+// Product enumerates the cartesian product of the ranges 0 to sizes[i]-1,
+// like nested loops with the last index in the innermost loop.
+type Product struct {
+	sizes         []int
+	indices       []int
+	started, done bool
+}
+
+func MakeProduct(sizes ...int) *Product {
+	return &Product{
+		sizes:   append([]int(nil), sizes...),
+		indices: make([]int, len(sizes)),
+	}
+}
+
+// Next advances to the next tuple and returns false if there is none.
+func (p *Product) Next() bool {
+	if p.done {
+		return false
+	}
+	if !p.started {
+		p.started = true
+		for _, size := range p.sizes {
+			if size <= 0 {
+				p.done = true
+				return false
+			}
+		}
+		return true
+	}
+
+	for i := len(p.indices) - 1; i >= 0; i-- {
+		p.indices[i]++
+		if p.indices[i] < p.sizes[i] {
+			return true
+		}
+		p.indices[i] = 0
+	}
+	p.done = true
+	return false
+}
+
+// Indices returns the current tuple.
+func (p *Product) Indices() []int {
+	return p.indices
+}
@@ -0,0 +1,115 @@
+package combinatorics
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+	"testing"
+)
+
+// Collects all results of an iterator as strings, checking that they are
+// unique.
+func collect(t *testing.T, next func() bool, indices func() []int) []string {
+	t.Helper()
+	var result []string
+	seen := make(map[string]bool)
+	for next() {
+		key := fmt.Sprint(indices())
+		if seen[key] {
+			t.Fatalf("duplicate %s", key)
+		}
+		seen[key] = true
+		result = append(result, key)
+	}
+	if next() {
+		t.Fatal("Next returned true after the end")
+	}
+	return result
+}
+
+func TestPermutations(t *testing.T) {
+	for n, count := range []int{1, 1, 2, 6, 24, 120, 720} {
+		p := MakePermutations(n)
+		if got := len(collect(t, p.Next, p.Indices)); got != count {
+			t.Errorf("%d: got %d permutations, want %d", n, got, count)
+		}
+	}
+
+	p := MakePermutations(3)
+	got := collect(t, p.Next, p.Indices)
+	want := []string{"[0 1 2]", "[1 0 2]", "[2 0 1]", "[0 2 1]", "[1 2 0]", "[2 1 0]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCombinations(t *testing.T) {
+	c := MakeCombinations(4, 2)
+	got := collect(t, c.Next, c.Indices)
+	want := []string{"[0 1]", "[0 2]", "[0 3]", "[1 2]", "[1 3]", "[2 3]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	tests := []struct{ n, k, count int }{
+		{5, 0, 1}, {5, 5, 1}, {5, 6, 0}, {10, 3, 120}, {0, 0, 1},
+	}
+	for _, test := range tests {
+		c := MakeCombinations(test.n, test.k)
+		if got := len(collect(t, c.Next, c.Indices)); got != test.count {
+			t.Errorf("%d out of %d: got %d, want %d", test.k, test.n, got, test.count)
+		}
+	}
+}
+
+func TestSubsets(t *testing.T) {
+	s := MakeSubsets(3)
+	got := collect(t, s.Next, s.Indices)
+	want := []string{"[]", "[0]", "[1]", "[0 1]", "[2]", "[0 2]", "[1 2]", "[0 1 2]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestGraySubsets(t *testing.T) {
+	g := MakeGraySubsets(5)
+	seen := make(map[uint64]bool)
+	var last uint64
+	for g.Next() {
+		mask := g.Mask()
+		if seen[mask] {
+			t.Fatalf("duplicate subset %05b", mask)
+		}
+		seen[mask] = true
+
+		if len(seen) == 1 {
+			if mask != 0 || g.Changed() != -1 {
+				t.Errorf("first subset is %05b with change %d", mask, g.Changed())
+			}
+		} else if diff := mask ^ last; bits.OnesCount64(diff) != 1 || diff != 1<<uint(g.Changed()) {
+			t.Errorf("%05b to %05b with change %d", last, mask, g.Changed())
+		}
+
+		if g.Contains(g.Changed()) != (mask&^last != 0) {
+			t.Errorf("Contains does not match the change from %05b to %05b", last, mask)
+		}
+		last = mask
+	}
+	if len(seen) != 32 {
+		t.Errorf("got %d subsets, want 32", len(seen))
+	}
+}
+
+func TestProduct(t *testing.T) {
+	p := MakeProduct(2, 3)
+	got := collect(t, p.Next, p.Indices)
+	want := []string{"[0 0]", "[0 1]", "[0 2]", "[1 0]", "[1 1]", "[1 2]"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	p = MakeProduct(3, 0)
+	if p.Next() {
+		t.Errorf("product with an empty range is not empty")
+	}
+}
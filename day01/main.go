@@ -1,4 +1,4 @@
-package main
+package day01
 
 import (
 	"bufio"
@@ -7,8 +7,8 @@ import (
 	"strconv"
 )
 
-func main() {
-	modules := readNumbers("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	modules := readNumbers(filename)
 
 	total, totalRecursive := 0, 0
 	for _, mass := range modules {
@@ -24,15 +24,7 @@ func main() {
 		}
 	}
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(total)
-	}
-
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(totalRecursive)
-	}
+	return fmt.Sprint(total), fmt.Sprint(totalRecursive)
 }
 
 func readNumbers(filename string) []int {
@@ -0,0 +1,33 @@
+package day01
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		mass             string
+		partOne, partTwo string
+	}{
+		{"12", "2", "2"},
+		{"14", "2", "2"},
+		{"1969", "654", "966"},
+		{"100756", "33583", "50346"},
+	}
+
+	for _, test := range tests {
+		partOne, partTwo := aoctest.Solve(t, Solve, test.mass)
+		aoctest.Check(t, test.mass+" part one", partOne, test.partOne)
+		aoctest.Check(t, test.mass+" part two", partTwo, test.partTwo)
+	}
+}
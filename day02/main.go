@@ -1,42 +1,38 @@
-package main
+package day02
 
 import (
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+
+	"greenlightning.eu/aoc19/intcode"
 )
 
-func main() {
-	input := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	values, err := intcode.LoadProgram(filename)
+	check(err)
 
 	var program []int
-	for _, value := range strings.Split(input, ",") {
-		program = append(program, toInt(value))
+	for _, value := range values {
+		program = append(program, int(value))
 	}
 
 	{
-		fmt.Println("--- Part One ---")
 		result, fault := emulate(program, 12, 02)
 		if fault {
 			panic("unexpected fault")
 		}
-		fmt.Println(result)
+		partOne = fmt.Sprint(result)
 	}
 
-	{
-		fmt.Println("--- Part Two ---")
-	loop:
-		for noun := 0; noun < 100; noun++ {
-			for verb := 0; verb < 100; verb++ {
-				result, _ := emulate(program, noun, verb)
-				if result == 19690720 {
-					fmt.Printf("%02d%02d\n", noun, verb)
-					break loop
-				}
+	for noun := 0; noun < 100; noun++ {
+		for verb := 0; verb < 100; verb++ {
+			result, _ := emulate(program, noun, verb)
+			if result == 19690720 {
+				return partOne, fmt.Sprintf("%02d%02d", noun, verb)
 			}
 		}
 	}
+
+	panic("no noun and verb found")
 }
 
 func emulate(program []int, noun, verb int) (result int, fault bool) {
@@ -67,18 +63,6 @@ func emulate(program []int, noun, verb int) (result int, fault bool) {
 	}
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
-func toInt(s string) int {
-	result, err := strconv.Atoi(s)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,48 @@
+package day02
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	// The value of the first position after running each program.
+	tests := []struct {
+		program string
+		result  int
+	}{
+		{"1,9,10,3,2,3,11,0,99,30,40,50", 3500},
+		{"1,0,0,0,99", 2},
+		{"2,3,0,3,99", 2},
+		{"2,4,4,5,99,0", 2},
+		{"1,1,1,4,99,5,6,0,99", 30},
+	}
+
+	for _, test := range tests {
+		var program []int
+		for _, value := range strings.Split(test.program, ",") {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			program = append(program, number)
+		}
+
+		// Use the noun and verb of the program itself.
+		result, fault := emulate(program, program[1], program[2])
+		if fault || result != test.result {
+			t.Errorf("%s: got %d (fault %v), want %d", test.program, result, fault, test.result)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package day03
 
 import (
 	"bufio"
@@ -9,24 +9,17 @@ import (
 	"strings"
 )
 
-func main() {
-	lines := readLines("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	lines := readLines(filename)
 
 	grid := make(map[Vector2]int)
 
 	parseSegment := func(segment string) (dir Vector2, length int) {
-		switch segment[0] {
-		case 'R':
-			dir.x++
-		case 'L':
-			dir.x--
-		case 'U':
-			dir.y++
-		case 'D':
-			dir.y--
+		d, ok := ParseDirection(rune(segment[0]))
+		if !ok {
+			panic(fmt.Sprintf("invalid direction: %q", segment))
 		}
-		length = toInt(segment[1:])
-		return
+		return d.Vector(), toInt(segment[1:])
 	}
 
 	pos, steps := Vector2{}, 0
@@ -56,15 +49,7 @@ func main() {
 		}
 	}
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(closestManhatten)
-	}
-
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(closestSteps)
-	}
+	return fmt.Sprint(closestManhatten), fmt.Sprint(closestSteps)
 }
 
 type Vector2 struct {
@@ -78,6 +63,110 @@ func (v Vector2) Plus(other Vector2) Vector2 {
 	}
 }
 
+// Direction is one of the eight compass directions, in clockwise order. The
+// y axis points down, like on screen and in the puzzle inputs.
+type Direction int
+
+const (
+	Up        Direction = 0
+	UpRight   Direction = 1
+	Right     Direction = 2
+	DownRight Direction = 3
+	Down      Direction = 4
+	DownLeft  Direction = 5
+	Left      Direction = 6
+	UpLeft    Direction = 7
+)
+
+var (
+	Directions4 = []Direction{Up, Right, Down, Left}
+	Directions8 = []Direction{Up, UpRight, Right, DownRight, Down, DownLeft, Left, UpLeft}
+)
+
+var directionVectors = [8]Vector2{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+func (d Direction) TurnLeft() Direction {
+	return (d + 6) % 8
+}
+
+func (d Direction) TurnRight() Direction {
+	return (d + 2) % 8
+}
+
+func (d Direction) Reverse() Direction {
+	return (d + 4) % 8
+}
+
+func (d Direction) Vector() Vector2 {
+	return directionVectors[d]
+}
+
+// Returns the direction of a vector of length one (or a diagonal one).
+func DirectionOf(v Vector2) (Direction, bool) {
+	for d, dv := range directionVectors {
+		if dv == v {
+			return Direction(d), true
+		}
+	}
+	return 0, false
+}
+
+// Parses a direction given as a letter (U, D, L, R or N, S, W, E) or as an
+// arrow (^, v, <, >).
+func ParseDirection(char rune) (Direction, bool) {
+	switch char {
+	case 'U', 'N', '^':
+		return Up, true
+	case 'D', 'S', 'v':
+		return Down, true
+	case 'L', 'W', '<':
+		return Left, true
+	case 'R', 'E', '>':
+		return Right, true
+	}
+	return 0, false
+}
+
+// The movement commands of intcode robots: 1 north, 2 south, 3 west, 4 east.
+var directionCommands = map[Direction]int64{Up: 1, Down: 2, Left: 3, Right: 4}
+
+func (d Direction) Command() int64 {
+	command, ok := directionCommands[d]
+	if !ok {
+		panic("no command for diagonal direction")
+	}
+	return command
+}
+
+func DirectionOfCommand(command int64) (Direction, bool) {
+	for d, c := range directionCommands {
+		if c == command {
+			return d, true
+		}
+	}
+	return 0, false
+}
+
+func (v Vector2) Move(d Direction) Vector2 {
+	return v.Plus(d.Vector())
+}
+
+func (v Vector2) Neighbors4() []Vector2 {
+	return v.neighbors(Directions4)
+}
+
+func (v Vector2) Neighbors8() []Vector2 {
+	return v.neighbors(Directions8)
+}
+
+func (v Vector2) neighbors(directions []Direction) []Vector2 {
+	result := make([]Vector2, len(directions))
+	for i, d := range directions {
+		result[i] = v.Move(d)
+	}
+	return result
+}
+
 func (v Vector2) Minus(other Vector2) Vector2 {
 	return Vector2{
 		x: v.x - other.x,
@@ -0,0 +1,32 @@
+package day03
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		wires            string
+		partOne, partTwo string
+	}{
+		{"R8,U5,L5,D3\nU7,R6,D4,L4", "6", "30"},
+		{"R75,D30,R83,U83,L12,D49,R71,U7,L72\nU62,R66,U55,R34,D71,R55,D58,R83", "159", "610"},
+		{"R98,U47,R26,D63,R33,U87,L62,D20,R33,U53,R51\nU98,R91,D20,R16,D67,R40,U7,R15,U6,R7", "135", "410"},
+	}
+
+	for _, test := range tests {
+		partOne, partTwo := aoctest.Solve(t, Solve, test.wires)
+		aoctest.Check(t, "part one", partOne, test.partOne)
+		aoctest.Check(t, "part two", partTwo, test.partTwo)
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package day04
 
 import (
 	"fmt"
@@ -8,8 +8,8 @@ import (
 	"strings"
 )
 
-func main() {
-	input := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	input := readFile(filename)
 	regex := regexp.MustCompile(`^(\d{6})-(\d{6})$`)
 	match := regex.FindStringSubmatch(input)
 	min, max := toInt(match[1]), toInt(match[2])
@@ -46,15 +46,7 @@ func main() {
 		}
 	}
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(total)
-	}
-
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(isolatedTotal)
-	}
+	return fmt.Sprint(total), fmt.Sprint(isolatedTotal)
 }
 
 func readFile(filename string) string {
@@ -0,0 +1,36 @@
+package day04
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	// A range of a single password counts whether the password is valid.
+	tests := []struct {
+		password         string
+		partOne, partTwo string
+	}{
+		{"111111", "1", "0"},
+		{"223450", "0", "0"},
+		{"123789", "0", "0"},
+		{"112233", "1", "1"},
+		{"123444", "1", "0"},
+		{"111122", "1", "1"},
+	}
+
+	for _, test := range tests {
+		partOne, partTwo := aoctest.Solve(t, Solve, test.password+"-"+test.password)
+		aoctest.Check(t, test.password+" part one", partOne, test.partOne)
+		aoctest.Check(t, test.password+" part two", partTwo, test.partTwo)
+	}
+}
@@ -1,39 +1,39 @@
-package main
+package day05
 
 import (
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+
+	"greenlightning.eu/aoc19/intcode"
 )
 
-func main() {
-	input := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	values, err := intcode.LoadProgram(filename)
+	check(err)
 
 	var program []int
-	for _, value := range strings.Split(input, ",") {
-		program = append(program, toInt(value))
+	for _, value := range values {
+		program = append(program, int(value))
 	}
 
 	{
-		fmt.Println("--- Part One ---")
 		output := emulate(program, []int{1})
 		for i := 0; i < len(output)-1; i++ {
 			if output[i] != 0 {
 				panic(fmt.Sprintf("test failure: %v", output))
 			}
 		}
-		fmt.Println(output[len(output)-1])
+		partOne = fmt.Sprint(output[len(output)-1])
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
 		output := emulate(program, []int{5})
 		if len(output) != 1 {
 			panic(fmt.Sprintf("unexpected output: %v", output))
 		}
-		fmt.Println(output[0])
+		partTwo = fmt.Sprint(output[0])
 	}
+
+	return partOne, partTwo
 }
 
 func emulate(program []int, input []int) (output []int) {
@@ -125,18 +125,6 @@ func emulate(program []int, input []int) (output []int) {
 	}
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
-func toInt(s string) int {
-	result, err := strconv.Atoi(s)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,69 @@
+package day05
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	const large = "3,21,1008,21,8,20,1005,20,22,107,8,21,20,1006,20,31,1106,0,36,98,0,0,1002,21,125,20,4,20,1105,1,46,104,999,1105,1,46,1101,1000,1,20,4,20,1105,1,46,98,99"
+
+	tests := []struct {
+		program string
+		input   int
+		output  []int
+	}{
+		// Multiplication and negative numbers in immediate mode.
+		{"1002,4,3,4,33", 0, nil},
+		{"1101,100,-1,4,0", 0, nil},
+
+		// Equal to 8 and less than 8, in position and immediate mode.
+		{"3,9,8,9,10,9,4,9,99,-1,8", 8, []int{1}},
+		{"3,9,8,9,10,9,4,9,99,-1,8", 7, []int{0}},
+		{"3,9,7,9,10,9,4,9,99,-1,8", 7, []int{1}},
+		{"3,9,7,9,10,9,4,9,99,-1,8", 8, []int{0}},
+		{"3,3,1108,-1,8,3,4,3,99", 8, []int{1}},
+		{"3,3,1108,-1,8,3,4,3,99", 9, []int{0}},
+		{"3,3,1107,-1,8,3,4,3,99", 7, []int{1}},
+		{"3,3,1107,-1,8,3,4,3,99", 8, []int{0}},
+
+		// Jumps, which output whether the input is non-zero.
+		{"3,12,6,12,15,1,13,14,13,4,13,99,-1,0,1,9", 0, []int{0}},
+		{"3,12,6,12,15,1,13,14,13,4,13,99,-1,0,1,9", 5, []int{1}},
+		{"3,3,1105,-1,9,1101,0,0,12,4,12,99,1", 0, []int{0}},
+		{"3,3,1105,-1,9,1101,0,0,12,4,12,99,1", 5, []int{1}},
+
+		// Compares the input to 8.
+		{large, 7, []int{999}},
+		{large, 8, []int{1000}},
+		{large, 9, []int{1001}},
+	}
+
+	for _, test := range tests {
+		var program []int
+		for _, value := range strings.Split(test.program, ",") {
+			number, err := strconv.Atoi(value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			program = append(program, number)
+		}
+
+		output := emulate(program, []int{test.input})
+		if !reflect.DeepEqual(output, test.output) {
+			t.Errorf("%s with input %d: got %v, want %v", test.program, test.input, output, test.output)
+		}
+	}
+}
@@ -1,4 +1,4 @@
-package main
+package day06
 
 import (
 	"bufio"
@@ -7,8 +7,8 @@ import (
 	"strings"
 )
 
-func main() {
-	lines := readLines("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	lines := readLines(filename)
 
 	// A map from the object in orbit to the object it is orbiting around.
 	// This stores the parent-of relationship in the orbit tree.
@@ -20,8 +20,6 @@ func main() {
 	}
 
 	{
-		fmt.Println("--- Part One ---")
-
 		// > What is the total number of direct and indirect orbits in your
 		// > map data?
 
@@ -42,12 +40,10 @@ func main() {
 			}
 		}
 
-		fmt.Println(total)
+		partOne = fmt.Sprint(total)
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
-
 		// > What is the minimum number of orbital transfers required to move
 		// > from the object YOU are orbiting to the object SAN is orbiting?
 
@@ -98,8 +94,10 @@ func main() {
 			distance++
 		}
 
-		fmt.Println(distance)
+		partTwo = fmt.Sprint(distance)
 	}
+
+	return partOne, partTwo
 }
 
 func readLines(filename string) []string {
@@ -0,0 +1,25 @@
+package day06
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExample(t *testing.T) {
+	// The example of part two, which adds YOU and SAN to the example of
+	// part one (42 orbits) with 7 and 5 more orbits.
+	const orbits = "COM)B\nB)C\nC)D\nD)E\nE)F\nB)G\nG)H\nD)I\nE)J\nJ)K\nK)L\nK)YOU\nI)SAN"
+
+	partOne, partTwo := aoctest.Solve(t, Solve, orbits)
+	aoctest.Check(t, "part one", partOne, "54")
+	aoctest.Check(t, "part two", partTwo, "4")
+}
@@ -1,34 +1,42 @@
-package main
+package day07
 
 import (
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+
+	"greenlightning.eu/aoc19/combinatorics"
+	"greenlightning.eu/aoc19/intcode"
 )
 
-func main() {
-	input := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	values, err := intcode.LoadProgram(filename)
+	check(err)
 
 	var program []int
-	for _, value := range strings.Split(input, ",") {
-		program = append(program, toInt(value))
+	for _, value := range values {
+		program = append(program, int(value))
 	}
 
 	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(findBestSignal(program, []int{0, 1, 2, 3, 4}))
+		bestSignal := findBestSignal(program, []int{0, 1, 2, 3, 4})
+		partOne = fmt.Sprint(bestSignal)
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(findBestSignal(program, []int{5, 6, 7, 8, 9}))
+		bestSignal := findBestSignal(program, []int{5, 6, 7, 8, 9})
+		partTwo = fmt.Sprint(bestSignal)
 	}
+
+	return partOne, partTwo
 }
 
 func findBestSignal(program []int, phaseValues []int) int {
 	bestSignal := 0
-	for _, phaseSettings := range allPermutations(phaseValues) {
+	phaseSettings := make([]int, len(phaseValues))
+	permutations := combinatorics.MakePermutations(len(phaseValues))
+	for permutations.Next() {
+		for i, index := range permutations.Indices() {
+			phaseSettings[i] = phaseValues[index]
+		}
 		signal := emulateAmplifiers(program, phaseSettings)
 		bestSignal = max(bestSignal, signal)
 	}
@@ -176,34 +184,6 @@ func pow(a, b int) int {
 	return p
 }
 
-func allPermutations(values []int) (result [][]int) {
-	if len(values) == 1 {
-		result = append(result, values)
-		return
-	}
-	for i, current := range values {
-		others := make([]int, 0, len(values)-1)
-		others = append(others, values[:i]...)
-		others = append(others, values[i+1:]...)
-		for _, route := range allPermutations(others) {
-			result = append(result, append(route, current))
-		}
-	}
-	return
-}
-
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
-func toInt(s string) int {
-	result, err := strconv.Atoi(s)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
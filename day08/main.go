@@ -1,83 +1,95 @@
-package main
+package day08
 
 import (
+	"flag"
 	"fmt"
+	"image/color"
 	"io/ioutil"
-	"math"
+	"os"
+	"path/filepath"
 	"strings"
+
+	"greenlightning.eu/aoc19/ocr"
+	"greenlightning.eu/aoc19/render"
+	"greenlightning.eu/aoc19/sif"
 )
 
-func main() {
-	input := []byte(readFile("input.txt"))
+var Flags = flag.NewFlagSet("day08", flag.ContinueOnError)
 
-	// Convert from character values to numbers.
-	for i := range input {
-		input[i] -= '0'
-	}
+var (
+	widthFlag  = Flags.Int("width", 25, "width of the image in pixels")
+	heightFlag = Flags.Int("height", 6, "height of the image in pixels")
 
-	width, height := 25, 6
-	size := width * height
-	layers := len(input) / size
+	printFlag  = Flags.Bool("print", false, "print the decoded image in addition to the recognized letters")
+	renderFlag = Flags.String("render", "", "render the decoded image to `file` (.png, .svg, .ans or text; - for the terminal)")
 
-	{
-		fmt.Println("--- Part One ---")
-
-		bestHistogram := [3]int{math.MaxInt32, math.MaxInt32, math.MaxInt32}
-		for layer := 0; layer < layers; layer++ {
-			var histogram [3]int
-			for y := 0; y < height; y++ {
-				for x := 0; x < width; x++ {
-					value := input[layer*size+y*width+x]
-					histogram[value]++
-				}
-			}
-			if histogram[0] < bestHistogram[0] {
-				bestHistogram = histogram
-			}
-		}
+	pngFlag    = Flags.String("png", "", "export the decoded image to a PNG `file`")
+	layersFlag = Flags.String("layers", "", "export each layer to a PNG file in `directory`")
+	encodeFlag = Flags.String("encode", "", "encode comma-separated PNG `files` (one per layer, front to back) and print the digits instead of solving the puzzle")
+)
 
-		fmt.Println(bestHistogram[1] * bestHistogram[2])
+var style = render.Style{
+	Palette: map[int]render.Tile{
+		sif.Black:       {Glyph: '.', Color: color.Black},
+		sif.White:       {Glyph: '#', Color: color.White},
+		sif.Transparent: {Glyph: '?'},
+	},
+}
+
+func Solve(filename string) (partOne, partTwo string) {
+	if *encodeFlag != "" {
+		img, err := sif.LoadPNGs(strings.Split(*encodeFlag, ",")...)
+		check(err)
+		fmt.Printf("%s\n", img.Encode())
+		return
 	}
 
+	data, err := ioutil.ReadFile(filename)
+	check(err)
+
+	img, err := sif.Decode(data, *widthFlag, *heightFlag)
+	check(err)
+
+	partOne = fmt.Sprint(img.Checksum())
+
 	{
-		fmt.Println("--- Part Two ---")
+		pixels := img.Flatten()
+
+		decoded := render.MakeDense(img.Width, img.Height)
+		for i, pixel := range pixels {
+			decoded.Values[i] = int(pixel)
+		}
+
+		// Fall back to printing the image if the letters cannot be
+		// recognized.
+		letters, err := ocr.Read(decoded)
+		if err == nil {
+			partTwo = letters
+		}
+		if err != nil || *printFlag {
+			check(render.Text(os.Stdout, decoded, style))
+		}
+		if err != nil {
+			fmt.Println(err)
+		}
 
-		image := make([]byte, size)
-		for i := range image {
-			image[i] = 2 // transparent
+		if *renderFlag != "" {
+			check(render.WriteFile(*renderFlag, decoded, style))
 		}
 
-		for layer := 0; layer < layers; layer++ {
-			for y := 0; y < height; y++ {
-				for x := 0; x < width; x++ {
-					value := input[layer*size+y*width+x]
-					if image[y*width+x] == 2 {
-						image[y*width+x] = value
-					}
-				}
-			}
+		if *pngFlag != "" {
+			check(img.SavePNG(*pngFlag, pixels))
 		}
+	}
 
-		for y := 0; y < height; y++ {
-			for x := 0; x < width; x++ {
-				switch image[y*width+x] {
-				case 0:
-					fmt.Print(".")
-				case 1:
-					fmt.Print("#")
-				default:
-					fmt.Print("?")
-				}
-			}
-			fmt.Println()
+	if *layersFlag != "" {
+		check(os.MkdirAll(*layersFlag, 0755))
+		for index, layer := range img.Layers {
+			check(img.SavePNG(filepath.Join(*layersFlag, fmt.Sprintf("layer-%03d.png", index)), layer))
 		}
 	}
-}
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
+	return partOne, partTwo
 }
 
 func check(err error) {
@@ -0,0 +1,39 @@
+package day08
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestLetters(t *testing.T) {
+	rows := []string{
+		"#..#..##..###...##..####.",
+		"#..#.#..#.#..#.#..#.#....",
+		"####.#....###..#....###..",
+		"#..#.#.##.#..#.#....#....",
+		"#..#.#..#.#..#.#..#.#....",
+		"#..#..###.###...##..#....",
+	}
+	digits := strings.NewReplacer("#", "1", ".", "0")
+
+	// The front layer contains the first row of the banner and is
+	// transparent elsewhere, so it has the fewest 0 digits. The back layer
+	// contains the whole banner.
+	front := digits.Replace(rows[0]) + strings.Repeat("2", 5*25)
+	back := digits.Replace(strings.Join(rows, ""))
+
+	partOne, partTwo := aoctest.Solve(t, Solve, front+back+"\n")
+	aoctest.Check(t, "part one", partOne, fmt.Sprint(13*125))
+	aoctest.Check(t, "part two", partTwo, "HGBCF")
+}
@@ -1,37 +1,32 @@
-package main
+package day09
 
 import (
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
-)
 
-func main() {
-	input := readFile("input.txt")
+	"greenlightning.eu/aoc19/intcode"
+)
 
-	var program []int64
-	for _, value := range strings.Split(input, ",") {
-		program = append(program, toInt64(value))
-	}
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
 
 	{
-		fmt.Println("--- Part One ---")
 		output := emulate(program, []int64{1})
 		if len(output) != 1 {
 			panic(fmt.Sprintf("unexpected output: %v", output))
 		}
-		fmt.Println(output[0])
+		partOne = fmt.Sprint(output[0])
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
 		output := emulate(program, []int64{2})
 		if len(output) != 1 {
 			panic(fmt.Sprintf("unexpected output: %v", output))
 		}
-		fmt.Println(output[0])
+		partTwo = fmt.Sprint(output[0])
 	}
+
+	return partOne, partTwo
 }
 
 func emulate(program []int64, input []int64) (output []int64) {
@@ -153,18 +148,6 @@ func pow(a, b int64) int64 {
 	return p
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,35 @@
+package day09
+
+import (
+	"reflect"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	quine := []int64{109, 1, 204, -1, 1001, 100, 1, 100, 1008, 100, 16, 101, 1006, 101, 0, 99}
+
+	tests := []struct {
+		program []int64
+		output  []int64
+	}{
+		{quine, quine},
+		{[]int64{1102, 34915192, 34915192, 7, 4, 7, 99, 0}, []int64{1219070632396864}},
+		{[]int64{104, 1125899906842624, 99}, []int64{1125899906842624}},
+	}
+
+	for _, test := range tests {
+		if output := emulate(test.program, nil); !reflect.DeepEqual(output, test.output) {
+			t.Errorf("%v: got %v, want %v", test.program, output, test.output)
+		}
+	}
+}
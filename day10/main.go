@@ -1,46 +1,38 @@
-package main
+package day10
 
 import (
 	"bufio"
 	"flag"
 	"fmt"
+	"image"
+	"image/color"
 	"math"
 	"os"
 	"sort"
 	"strconv"
+
+	"greenlightning.eu/aoc19/grid"
+	"greenlightning.eu/aoc19/render"
 )
 
-var printFlag = flag.Bool("print", false, "print map as the 200th asteroid is destroyed")
+var Flags = flag.NewFlagSet("day10", flag.ContinueOnError)
 
-func main() {
-	flag.Parse()
+var (
+	printFlag  = Flags.Bool("print", false, "print map as the 200th asteroid is destroyed")
+	renderFlag = Flags.String("render", "", "render map as the 200th asteroid is destroyed to `file` (.png, .svg, .ans or text; - for the terminal)")
+)
 
-	lines := readLines("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	field := grid.Parse(readLines(filename))
 
 	asteroids := make(map[Vector2]bool)
-
-	for y, line := range lines {
-		for x, char := range line {
-			if char == '#' {
-				asteroids[Vector2{x, y}] = true
-			}
-		}
+	for _, p := range grid.FindAll(field, '#') {
+		asteroids[Vector2{p.X, p.Y}] = true
 	}
 
-	var bestVisible int
-	var bestLocation Vector2
-	for location := range asteroids {
-		visible := len(findVisibleAsteroids(location, asteroids))
-		if visible > bestVisible {
-			bestVisible = visible
-			bestLocation = location
-		}
-	}
+	bestLocation, bestVisible := findBestLocation(asteroids)
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(bestVisible)
-	}
+	partOne = fmt.Sprint(bestVisible)
 
 	var vaporizationOrder []Vector2
 	for len(asteroids) > 1 {
@@ -65,30 +57,43 @@ func main() {
 
 	target := vaporizationOrder[199]
 
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(target.x*100 + target.y)
-	}
+	partTwo = fmt.Sprint(target.x*100 + target.y)
 
-	if *printFlag {
+	if *printFlag || *renderFlag != "" {
+		remaining := render.MakeDense(field.Width, field.Height)
 		for _, asteroid := range vaporizationOrder[200:] {
-			asteroids[asteroid] = true
+			remaining.Set(image.Point{asteroid.x, asteroid.y}, 1)
 		}
-		for y, line := range lines {
-			for x := range line {
-				if x == bestLocation.x && y == bestLocation.y {
-					fmt.Print("X")
-				} else if x == target.x && y == target.y {
-					fmt.Print("O")
-				} else if asteroids[Vector2{x, y}] {
-					fmt.Print("#")
-				} else {
-					fmt.Print(".")
-				}
-			}
-			fmt.Println()
+
+		style := render.Style{
+			Palette: map[int]render.Tile{
+				0: {Glyph: '.', Color: color.Black},
+				1: {Glyph: '#', Color: color.Gray{0x80}},
+			},
+		}
+		style.Highlight(image.Point{bestLocation.x, bestLocation.y}, render.Tile{Glyph: 'X', Color: color.RGBA{0x40, 0x80, 0xff, 0xff}})
+		style.Highlight(image.Point{target.x, target.y}, render.Tile{Glyph: 'O', Color: color.RGBA{0xff, 0x40, 0x40, 0xff}})
+
+		if *printFlag {
+			check(render.Text(os.Stdout, remaining, style))
+		}
+		if *renderFlag != "" {
+			check(render.WriteFile(*renderFlag, remaining, style))
+		}
+	}
+
+	return partOne, partTwo
+}
+
+func findBestLocation(asteroids map[Vector2]bool) (bestLocation Vector2, bestVisible int) {
+	for location := range asteroids {
+		visible := len(findVisibleAsteroids(location, asteroids))
+		if visible > bestVisible {
+			bestVisible = visible
+			bestLocation = location
 		}
 	}
+	return bestLocation, bestVisible
 }
 
 func findVisibleAsteroids(location Vector2, asteroids map[Vector2]bool) []Vector2 {
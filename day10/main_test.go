@@ -0,0 +1,71 @@
+package day10
+
+import (
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+const large = `.#..##.###...#######
+##.############..##.
+.#.######.########.#
+.###.#######.####.#.
+#####.##.#.##.###.##
+..#####..#.#########
+####################
+#.####....###.#.#.##
+##.#################
+#####.##.###..####..
+..######..##.#######
+####.##.####...##..#
+.#####..#.######.###
+##...#.##########...
+#.##########.#######
+.####.#.###.###.#.##
+....##.##.###..#####
+.#.#.###########.###
+#.#.#.#####.####.###
+###.##.####.##.#..##`
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestBestLocation(t *testing.T) {
+	tests := []struct {
+		field    string
+		location Vector2
+		visible  int
+	}{
+		{".#..#\n.....\n#####\n....#\n...##", Vector2{3, 4}, 8},
+		{"......#.#.\n#..#.#....\n..#######.\n.#.#.###..\n.#..#.....\n..#....#.#\n#..#....#.\n.##.#..###\n##...#..#.\n.#....####", Vector2{5, 8}, 33},
+		{large, Vector2{11, 13}, 210},
+	}
+
+	for _, test := range tests {
+		asteroids := make(map[Vector2]bool)
+		for y, line := range strings.Split(test.field, "\n") {
+			for x, char := range line {
+				if char == '#' {
+					asteroids[Vector2{x, y}] = true
+				}
+			}
+		}
+
+		location, visible := findBestLocation(asteroids)
+		if location != test.location || visible != test.visible {
+			t.Errorf("got %v with %d visible, want %v with %d", location, visible, test.location, test.visible)
+		}
+	}
+}
+
+func TestVaporization(t *testing.T) {
+	partOne, partTwo := aoctest.Solve(t, Solve, large)
+	aoctest.Check(t, "part one", partOne, "210")
+	aoctest.Check(t, "part two", partTwo, "802")
+}
@@ -0,0 +1,153 @@
+package day11
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"os"
+
+	"greenlightning.eu/aoc19/render"
+)
+
+// Exports of a run of the robot (see -history, -heatmap and -animation flags).
+
+var robotTile = render.Tile{Glyph: 'R', Color: color.RGBA{0xff, 0x40, 0x40, 0xff}}
+
+func (run *Run) writeHistory(filename string) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	writer := bufio.NewWriter(file)
+	fmt.Fprintln(writer, "# move step x y color")
+	for _, event := range run.Events {
+		fmt.Fprintf(writer, "%d %d %d %d %d\n", event.Move, event.Step, event.Position.x, event.Position.y, event.Color)
+	}
+
+	// The path contains one more position than there are events.
+	end := run.Path[len(run.Path)-1]
+	fmt.Fprintf(writer, "# end %d %d\n", end.x, end.y)
+
+	if err := writer.Flush(); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Each panel shows how often it has been painted. In text, counts above nine
+// are shown as "+". In images, the color goes from blue to red as the count
+// increases.
+func (run *Run) writeHeatmap(filename string) error {
+	heatmap := make(render.Sparse)
+	maxCount := 0
+	for pos, count := range run.heatmap() {
+		heatmap[image.Point{pos.x, pos.y}] = count
+		maxCount = max(maxCount, count)
+	}
+
+	style := render.Style{
+		Palette: make(map[int]render.Tile),
+		Unknown: render.Tile{Glyph: ' '},
+	}
+
+	for count := 1; count <= maxCount; count++ {
+		glyph := '+'
+		if count <= 9 {
+			glyph = rune('0' + count)
+		}
+
+		heat := 1.0
+		if maxCount > 1 {
+			heat = float64(count-1) / float64(maxCount-1)
+		}
+
+		style.Palette[count] = render.Tile{
+			Glyph: glyph,
+			Color: color.RGBA{uint8(0x30 + 0xcf*heat), 0x30, uint8(0xff - 0xcf*heat), 0xff},
+		}
+	}
+
+	return render.WriteFile(filename, heatmap, style)
+}
+
+// Writes one frame per move, showing the hull and the robot after the move.
+func (run *Run) writeAnimation(filename string, scale, skip int) error {
+	skip = max(skip, 1)
+
+	positions := run.Path
+	for pos := range run.Hull {
+		positions = append(positions, pos)
+	}
+	bounds := boundsOf(positions)
+
+	palette := color.Palette{color.Black, color.White, robotTile.Color}
+	frameStyle := style
+	frameStyle.Scale = scale
+
+	hull := make(map[Vector2]int64)
+	for pos, color := range run.Start {
+		hull[pos] = color
+	}
+
+	var animation gif.GIF
+
+	addFrame := func(robot Vector2) {
+		grid := render.Func(bounds, func(p image.Point) (int, bool) {
+			color, ok := hull[Vector2{p.X, p.Y}]
+			return int(color), ok
+		})
+
+		frameStyle.Highlights = nil
+		frameStyle.Highlight(image.Point{robot.x, robot.y}, robotTile)
+
+		rgba := render.Image(grid, frameStyle)
+		frame := image.NewPaletted(rgba.Bounds(), palette)
+		draw.Draw(frame, frame.Bounds(), rgba, image.Point{}, draw.Src)
+
+		animation.Image = append(animation.Image, frame)
+		animation.Delay = append(animation.Delay, 5)
+	}
+
+	addFrame(run.Path[0])
+	for index, event := range run.Events {
+		hull[event.Position] = event.Color
+		if (index+1)%skip == 0 || index == len(run.Events)-1 {
+			addFrame(run.Path[index+1])
+		}
+	}
+
+	// Pause on the finished hull before looping.
+	animation.Delay[len(animation.Delay)-1] = 300
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := gif.EncodeAll(file, &animation); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Returns the smallest rectangle that contains all positions.
+func boundsOf(positions []Vector2) image.Rectangle {
+	var result image.Rectangle
+	for index, pos := range positions {
+		cell := image.Rect(pos.x, pos.y, pos.x+1, pos.y+1)
+		if index == 0 {
+			result = cell
+		} else {
+			result = result.Union(cell)
+		}
+	}
+	return result
+}
@@ -0,0 +1,83 @@
+package day11
+
+import (
+	"bufio"
+	"fmt"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Loads a starting hull (see -hull flag). Text files use "#" for white and
+// "." for black panels. In PNG files, bright pixels are white panels, dark
+// pixels are black panels and transparent pixels are left out. The origin is
+// the position in the file where the robot starts.
+func loadHull(filename string, origin Vector2) (map[Vector2]int64, error) {
+	if strings.ToLower(filepath.Ext(filename)) == ".png" {
+		return loadHullPNG(filename, origin)
+	}
+	return loadHullText(filename, origin)
+}
+
+func loadHullText(filename string, origin Vector2) (map[Vector2]int64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	hull := make(map[Vector2]int64)
+
+	scanner := bufio.NewScanner(file)
+	for y := 0; scanner.Scan(); y++ {
+		for x, char := range []rune(scanner.Text()) {
+			pos := Vector2{x - origin.x, y - origin.y}
+			switch char {
+			case '#':
+				hull[pos] = 1
+			case '.':
+				hull[pos] = 0
+			default:
+				return nil, fmt.Errorf("%s:%d:%d: unexpected character %q", filename, y+1, x+1, char)
+			}
+		}
+	}
+
+	return hull, scanner.Err()
+}
+
+func loadHullPNG(filename string, origin Vector2) (map[Vector2]int64, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	hull := make(map[Vector2]int64)
+
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			if c.A < 0x80 {
+				continue
+			}
+
+			pos := Vector2{x - bounds.Min.X - origin.x, y - bounds.Min.Y - origin.y}
+			if gray := color.GrayModel.Convert(color.RGBA{c.R, c.G, c.B, 0xff}).(color.Gray); gray.Y >= 0x80 {
+				hull[pos] = 1
+			} else {
+				hull[pos] = 0
+			}
+		}
+	}
+
+	return hull, nil
+}
@@ -1,230 +1,174 @@
-package main
+package day11
 
 import (
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+	"image"
+	"image/color"
+	"os"
+
+	"greenlightning.eu/aoc19/intcode"
+	"greenlightning.eu/aoc19/ocr"
+	"greenlightning.eu/aoc19/render"
+)
+
+var Flags = flag.NewFlagSet("day11", flag.ContinueOnError)
+
+var (
+	printFlag  = Flags.Bool("print", false, "print the painted hull in addition to the recognized letters")
+	renderFlag = Flags.String("render", "", "render the painted hull to `file` (.png, .svg, .ans or text; - for the terminal)")
+
+	hullFlag   = Flags.String("hull", "", "paint the hull from `file` (text with # and . or PNG) instead of solving the puzzle")
+	originFlag = Flags.String("origin", "0,0", "starting position of the robot on the hull from the file as `x,y`")
+
+	historyFlag        = Flags.String("history", "", "write the robot's path and paint events to `file`")
+	heatmapFlag        = Flags.String("heatmap", "", "render how often each panel was painted to `file` (.png, .svg, .ans or text; - for the terminal)")
+	animationFlag      = Flags.String("animation", "", "write an animated GIF of the robot painting the hull to `file`")
+	animationScaleFlag = Flags.Int("animation-scale", 8, "size of a panel in the animation in pixels")
+	animationSkipFlag  = Flags.Int("animation-skip", 1, "only add every n-th move to the animation")
 )
 
-func main() {
-	input := readFile("input.txt")
+var style = render.Style{
+	Palette: map[int]render.Tile{
+		0: {Glyph: '.', Color: color.Black},
+		1: {Glyph: '#', Color: color.White},
+	},
+	// Panels that have never been painted are black.
+	Unknown: render.Tile{Glyph: '.', Color: color.Black},
+}
+
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
+
+	if *hullFlag != "" {
+		var origin Vector2
+		_, err := fmt.Sscanf(*originFlag, "%d,%d", &origin.x, &origin.y)
+		check(err)
+
+		hull, err := loadHull(*hullFlag, origin)
+		check(err)
 
-	var program []int64
-	for _, value := range strings.Split(input, ",") {
-		program = append(program, toInt64(value))
+		run := emulateEmergencyHullPaintingRobot(program, hull)
+		fmt.Printf("Painted %d panels in %d moves.\n", len(run.heatmap()), len(run.Events))
+		if letters := report(run); letters != "" {
+			fmt.Println(letters)
+		}
+		return
 	}
 
 	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(len(emulateEmergencyHullPaintingRobot(program, 0)))
+		run := emulateEmergencyHullPaintingRobot(program, nil)
+		partOne = fmt.Sprint(len(run.heatmap()))
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
+		run := emulateEmergencyHullPaintingRobot(program, map[Vector2]int64{{0, 0}: 1})
+		partTwo = report(run)
+	}
 
-		grid := emulateEmergencyHullPaintingRobot(program, 1)
+	return partOne, partTwo
+}
 
-		var min, max Vector2
-		for pos := range grid {
-			min = min.Min(pos)
-			max = max.Max(pos)
-		}
+// Writes the outputs requested by the flags and returns the letters painted on
+// the hull, if they can be recognized.
+func report(run *Run) string {
+	hull := make(render.Sparse)
+	for pos, color := range run.Hull {
+		hull[image.Point{pos.x, pos.y}] = int(color)
+	}
 
-		for y := min.y; y <= max.y; y++ {
-			for x := min.x; x <= max.x; x++ {
-				if grid[Vector2{x, y}] == 1 {
-					fmt.Print("#")
-				} else {
-					fmt.Print(".")
-				}
-			}
-			fmt.Println()
-		}
+	// Fall back to printing the image if the letters cannot be recognized.
+	letters, err := ocr.Read(hull)
+	if err != nil || *printFlag {
+		check(render.Text(os.Stdout, hull, style))
+	}
+	if err != nil {
+		fmt.Println(err)
 	}
-}
 
-func emulateEmergencyHullPaintingRobot(program []int64, initialPanel int64) map[Vector2]int64 {
-	up := Vector2{0, -1}
-	right := Vector2{1, 0}
-	down := Vector2{0, 1}
-	left := Vector2{-1, 0}
-
-	input := make(chan int64, 1)
-	output := make(chan int64)
-	halt := make(chan bool)
-
-	go emulate(program, input, output, halt)
-
-	grid := make(map[Vector2]int64)
-	pos, dir := Vector2{0, 0}, up
-
-	grid[pos] = initialPanel
-
-	for {
-		input <- grid[pos]
-
-		select {
-		case value := <-output:
-			grid[pos] = value
-
-			if turn := <-output; turn == 1 {
-				// turn right
-				switch dir {
-				case up:
-					dir = right
-				case right:
-					dir = down
-				case down:
-					dir = left
-				case left:
-					dir = up
-				}
-			} else {
-				// turn left
-				switch dir {
-				case up:
-					dir = left
-				case left:
-					dir = down
-				case down:
-					dir = right
-				case right:
-					dir = up
-				}
-			}
-
-			pos = pos.Plus(dir)
-
-		case <-halt:
-			return grid
-		}
+	if *renderFlag != "" {
+		check(render.WriteFile(*renderFlag, hull, style))
 	}
+
+	if *historyFlag != "" {
+		check(run.writeHistory(*historyFlag))
+	}
+
+	if *heatmapFlag != "" {
+		check(run.writeHeatmap(*heatmapFlag))
+	}
+
+	if *animationFlag != "" {
+		check(run.writeAnimation(*animationFlag, *animationScaleFlag, *animationSkipFlag))
+	}
+
+	return letters
 }
 
-func emulate(program []int64, input <-chan int64, output chan<- int64, halt chan<- bool) {
-	// Copy the program into memory, so that we do not modify the original.
-	memory := make([]int64, len(program))
-	copy(memory, program)
+// A paint event records that the robot painted the panel at the position.
+// The move is the number of times the robot moved before and the step is the
+// number of instructions the robot's program executed up to that point.
+type PaintEvent struct {
+	Move     int
+	Step     int64
+	Position Vector2
+	Color    int64
+}
 
-	getMemoryPointer := func(index int64) *int64 {
-		// Grow memory, if index is out of range.
-		for int64(len(memory)) <= index {
-			memory = append(memory, 0)
-		}
-		return &memory[index]
+type Run struct {
+	// The color of each panel at the start and at the end of the run.
+	Start, Hull map[Vector2]int64
+
+	// The position of the robot before each move and at the end.
+	Path []Vector2
+
+	Events []PaintEvent
+}
+
+func emulateEmergencyHullPaintingRobot(program []int64, hull map[Vector2]int64) *Run {
+	emulator := intcode.MakeEmulator(program)
+
+	run := &Run{Start: hull, Hull: make(map[Vector2]int64)}
+	for pos, color := range hull {
+		run.Hull[pos] = color
 	}
 
-	var ip, relativeBase int64
-	for {
-		instruction := memory[ip]
-		opcode := instruction % 100
-
-		getParameter := func(offset int64) *int64 {
-			parameter := memory[ip+offset]
-			mode := instruction / pow(10, offset+1) % 10
-			switch mode {
-			case 0: // position mode
-				return getMemoryPointer(parameter)
-			case 1: // immediate mode
-				return &parameter
-			case 2: // relative mode
-				return getMemoryPointer(relativeBase + parameter)
-			default:
-				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", ip, instruction, offset, mode))
-			}
+	pos, dir := Vector2{0, 0}, Up
+
+	for move := 0; ; move++ {
+		run.Path = append(run.Path, pos)
+
+		value, status := emulator.Emulate(run.Hull[pos])
+		if status == intcode.EmulatorStatusHalted {
+			return run
 		}
 
-		switch opcode {
-
-		case 1: // ADD
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a + *b
-			ip += 4
-
-		case 2: // MULTIPLY
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a * *b
-			ip += 4
-
-		case 3: // INPUT
-			a := getParameter(1)
-			*a = <-input
-			ip += 2
-
-		case 4: // OUTPUT
-			a := getParameter(1)
-			output <- *a
-			ip += 2
-
-		case 5: // JUMP IF TRUE
-			a, b := getParameter(1), getParameter(2)
-			if *a != 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
-
-		case 6: // JUMP IF FALSE
-			a, b := getParameter(1), getParameter(2)
-			if *a == 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
-
-		case 7: // LESS THAN
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a < *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
-
-		case 8: // EQUAL
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a == *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
-
-		case 9: // RELATIVE BASE OFFSET
-			a := getParameter(1)
-			relativeBase += *a
-			ip += 2
-
-		case 99: // HALT
-			halt <- true
-			return
-
-		default:
-			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", ip, instruction, opcode))
+		turn, status := emulator.Emulate()
+		if status != intcode.EmulatorStatusOutput {
+			panic("expected output")
 		}
-	}
-}
 
-// Integer power: compute a**b using binary powering algorithm
-// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
-// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
-func pow(a, b int64) int64 {
-	var p int64 = 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
+		run.Hull[pos] = value
+		run.Events = append(run.Events, PaintEvent{move, emulator.Steps(), pos, value})
+
+		if turn == 1 {
+			dir = dir.TurnRight()
+		} else {
+			dir = dir.TurnLeft()
 		}
-		b >>= 1
-		a *= a
+
+		pos = pos.Move(dir)
 	}
-	return p
 }
 
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
+// Returns how often each panel has been painted.
+func (run *Run) heatmap() map[Vector2]int {
+	counts := make(map[Vector2]int)
+	for _, event := range run.Events {
+		counts[event.Position]++
+	}
+	return counts
 }
 
 type Vector2 struct {
@@ -238,37 +182,114 @@ func (v Vector2) Plus(other Vector2) Vector2 {
 	}
 }
 
-func (v Vector2) Min(other Vector2) Vector2 {
-	return Vector2{
-		x: min(v.x, other.x),
-		y: min(v.y, other.y),
+// Direction is one of the eight compass directions, in clockwise order. The
+// y axis points down, like on screen and in the puzzle inputs.
+type Direction int
+
+const (
+	Up        Direction = 0
+	UpRight   Direction = 1
+	Right     Direction = 2
+	DownRight Direction = 3
+	Down      Direction = 4
+	DownLeft  Direction = 5
+	Left      Direction = 6
+	UpLeft    Direction = 7
+)
+
+var (
+	Directions4 = []Direction{Up, Right, Down, Left}
+	Directions8 = []Direction{Up, UpRight, Right, DownRight, Down, DownLeft, Left, UpLeft}
+)
+
+var directionVectors = [8]Vector2{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+
+func (d Direction) TurnLeft() Direction {
+	return (d + 6) % 8
+}
+
+func (d Direction) TurnRight() Direction {
+	return (d + 2) % 8
+}
+
+func (d Direction) Reverse() Direction {
+	return (d + 4) % 8
+}
+
+func (d Direction) Vector() Vector2 {
+	return directionVectors[d]
+}
+
+// Returns the direction of a vector of length one (or a diagonal one).
+func DirectionOf(v Vector2) (Direction, bool) {
+	for d, dv := range directionVectors {
+		if dv == v {
+			return Direction(d), true
+		}
 	}
+	return 0, false
 }
 
-func (v Vector2) Max(other Vector2) Vector2 {
-	return Vector2{
-		x: max(v.x, other.x),
-		y: max(v.y, other.y),
+// Parses a direction given as a letter (U, D, L, R or N, S, W, E) or as an
+// arrow (^, v, <, >).
+func ParseDirection(char rune) (Direction, bool) {
+	switch char {
+	case 'U', 'N', '^':
+		return Up, true
+	case 'D', 'S', 'v':
+		return Down, true
+	case 'L', 'W', '<':
+		return Left, true
+	case 'R', 'E', '>':
+		return Right, true
 	}
+	return 0, false
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
+// The movement commands of intcode robots: 1 north, 2 south, 3 west, 4 east.
+var directionCommands = map[Direction]int64{Up: 1, Down: 2, Left: 3, Right: 4}
+
+func (d Direction) Command() int64 {
+	command, ok := directionCommands[d]
+	if !ok {
+		panic("no command for diagonal direction")
+	}
+	return command
 }
 
-func check(err error) {
-	if err != nil {
-		panic(err)
+func DirectionOfCommand(command int64) (Direction, bool) {
+	for d, c := range directionCommands {
+		if c == command {
+			return d, true
+		}
 	}
+	return 0, false
 }
 
-func min(x, y int) int {
-	if y < x {
-		return y
+func (v Vector2) Move(d Direction) Vector2 {
+	return v.Plus(d.Vector())
+}
+
+func (v Vector2) Neighbors4() []Vector2 {
+	return v.neighbors(Directions4)
+}
+
+func (v Vector2) Neighbors8() []Vector2 {
+	return v.neighbors(Directions8)
+}
+
+func (v Vector2) neighbors(directions []Direction) []Vector2 {
+	result := make([]Vector2, len(directions))
+	for i, d := range directions {
+		result[i] = v.Move(d)
+	}
+	return result
+}
+
+func check(err error) {
+	if err != nil {
+		panic(err)
 	}
-	return x
 }
 
 func max(x, y int) int {
@@ -0,0 +1,69 @@
+package day11
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExample(t *testing.T) {
+	// A robot that ignores the camera and paints and turns like the one in
+	// the puzzle statement.
+	var program []int64
+	for _, output := range [][2]int64{{1, 0}, {0, 0}, {1, 0}, {1, 0}, {0, 1}, {1, 0}, {1, 0}} {
+		program = append(program, 3, 100, 104, output[0], 104, output[1])
+	}
+	program = append(program, 99)
+
+	run := emulateEmergencyHullPaintingRobot(program, nil)
+
+	if got := len(run.heatmap()); got != 6 {
+		t.Errorf("painted %d panels, want 6", got)
+	}
+	if got := run.Path[len(run.Path)-1]; got != (Vector2{0, -1}) {
+		t.Errorf("robot ended at %v, want {0 -1}", got)
+	}
+
+	white := 0
+	for _, color := range run.Hull {
+		white += int(color)
+	}
+	if white != 4 {
+		t.Errorf("%d white panels, want 4", white)
+	}
+}
+
+func TestLoadHullText(t *testing.T) {
+	filename := aoctest.TempFile(t, "#..\n.#.\n")
+	defer os.Remove(filename)
+
+	hull, err := loadHull(filename, Vector2{1, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[Vector2]int64{
+		{-1, -1}: 1, {0, -1}: 0, {1, -1}: 0,
+		{-1, 0}: 0, {0, 0}: 1, {1, 0}: 0,
+	}
+	if !reflect.DeepEqual(hull, want) {
+		t.Errorf("got %v, want %v", hull, want)
+	}
+
+	bad := aoctest.TempFile(t, "#x\n")
+	defer os.Remove(bad)
+
+	if _, err := loadHull(bad, Vector2{}); err == nil {
+		t.Error("expected an error for an unknown character")
+	}
+}
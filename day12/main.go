@@ -1,36 +1,23 @@
-package main
+package day12
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"regexp"
-	"strconv"
+
+	"greenlightning.eu/aoc19/numtheory"
+	"greenlightning.eu/aoc19/parse"
 )
 
 type Moon struct {
 	pos, vel Vector3
 }
 
-func main() {
-	lines := readLines("input.txt")
-
-	var input []Moon
+var moonPattern = parse.MustCompile(`<x=(-?\d+), y=(-?\d+), z=(-?\d+)>`)
 
-	regex := regexp.MustCompile(`^<x=(-?\d+), y=(-?\d+), z=(-?\d+)>$`)
-	for _, line := range lines {
-		match := regex.FindStringSubmatch(line)
-		x, y, z := toInt(match[1]), toInt(match[2]), toInt(match[3])
-		moon := Moon{
-			pos: Vector3{x, y, z},
-			vel: Vector3{0, 0, 0},
-		}
-		input = append(input, moon)
-	}
+func Solve(filename string) (partOne, partTwo string) {
+	input, err := parseMoons(filename)
+	check(err)
 
 	{
-		fmt.Println("--- Part One ---")
-
 		moons := make([]Moon, len(input))
 		copy(moons, input)
 
@@ -44,12 +31,10 @@ func main() {
 			kineticEnergy := moon.vel.ManhattenLength()
 			totalEnergy += potentialEnergy * kineticEnergy
 		}
-		fmt.Println(totalEnergy)
+		partOne = fmt.Sprint(totalEnergy)
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
-
 		moons := make([]Moon, len(input))
 		copy(moons, input)
 
@@ -97,10 +82,31 @@ func main() {
 			}
 		}
 
-		result := lcm(xSteps, ySteps)
-		result = lcm(result, zSteps)
-		fmt.Println(result)
+		result, err := numtheory.LCM(int64(xSteps), int64(ySteps), int64(zSteps))
+		check(err)
+		partTwo = fmt.Sprint(result)
 	}
+
+	return partOne, partTwo
+}
+
+func parseMoons(filename string) ([]Moon, error) {
+	var moons []Moon
+
+	err := parse.Lines(filename, func(line string) error {
+		var x, y, z int
+		if err := moonPattern.Scan(line, &x, &y, &z); err != nil {
+			return err
+		}
+		moon := Moon{
+			pos: Vector3{x, y, z},
+			vel: Vector3{0, 0, 0},
+		}
+		moons = append(moons, moon)
+		return nil
+	})
+
+	return moons, err
 }
 
 func simulate(moons []Moon) {
@@ -152,26 +158,6 @@ func (v Vector3) ManhattenLength() int {
 	return abs(v.x) + abs(v.y) + abs(v.z)
 }
 
-func readLines(filename string) []string {
-	file, err := os.Open(filename)
-	check(err)
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	return lines
-}
-
-func toInt(s string) int {
-	result, err := strconv.Atoi(s)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -194,14 +180,3 @@ func sign(x int) int {
 	}
 	return 0
 }
-
-func gcd(a, b int) int {
-	for b != 0 {
-		a, b = b, a%b
-	}
-	return a
-}
-
-func lcm(a, b int) int {
-	return a / gcd(a, b) * b
-}
@@ -0,0 +1,64 @@
+package day12
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+const (
+	exampleOne = "<x=-1, y=0, z=2>\n<x=2, y=-10, z=-7>\n<x=4, y=-8, z=8>\n<x=3, y=5, z=-1>"
+	exampleTwo = "<x=-8, y=-10, z=0>\n<x=5, y=5, z=10>\n<x=2, y=-7, z=3>\n<x=9, y=-8, z=-3>"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestEnergy(t *testing.T) {
+	tests := []struct {
+		positions []Vector3
+		steps     int
+		energy    int
+	}{
+		{[]Vector3{{-1, 0, 2}, {2, -10, -7}, {4, -8, 8}, {3, 5, -1}}, 10, 179},
+		{[]Vector3{{-8, -10, 0}, {5, 5, 10}, {2, -7, 3}, {9, -8, -3}}, 100, 1940},
+	}
+
+	for _, test := range tests {
+		var moons []Moon
+		for _, pos := range test.positions {
+			moons = append(moons, Moon{pos: pos})
+		}
+		for step := 0; step < test.steps; step++ {
+			simulate(moons)
+		}
+
+		energy := 0
+		for _, moon := range moons {
+			energy += moon.pos.ManhattenLength() * moon.vel.ManhattenLength()
+		}
+		if energy != test.energy {
+			t.Errorf("after %d steps: got %d, want %d", test.steps, energy, test.energy)
+		}
+	}
+}
+
+func TestRepetition(t *testing.T) {
+	tests := []struct {
+		moons string
+		steps string
+	}{
+		{exampleOne, "2772"},
+		{exampleTwo, "4686774924"},
+	}
+
+	for _, test := range tests {
+		_, partTwo := aoctest.Solve(t, Solve, test.moons)
+		aoctest.Check(t, "part two", partTwo, test.steps)
+	}
+}
@@ -0,0 +1,183 @@
+package day13
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// GIF export of a game (see -gif flag). A frame is rendered each time the
+// cabinet waits for input. To keep the file small, each frame only contains
+// the rectangle that changed since the previous frame and frames without
+// changes extend the delay of the previous frame.
+
+// Default colors for Empty, Wall, Block, Paddle, Ball and the score.
+const defaultPalette = "000000,7f7f7f,d95763,5b6ee1,ffffff,fbf236"
+
+// 3x5 pixel font for the score.
+var digits = [10][5]string{
+	{"###", "#.#", "#.#", "#.#", "###"},
+	{".#.", "##.", ".#.", ".#.", "###"},
+	{"###", "..#", "###", "#..", "###"},
+	{"###", "..#", "###", "..#", "###"},
+	{"#.#", "#.#", "###", "..#", "..#"},
+	{"###", "#..", "###", "..#", "###"},
+	{"###", "#..", "###", "#.#", "###"},
+	{"###", "..#", "..#", "..#", "..#"},
+	{"###", "#.#", "###", "#.#", "###"},
+	{"###", "#.#", "###", "..#", "###"},
+}
+
+type Animation struct {
+	palette color.Palette
+	scale   int
+	skip    int
+	delay   int
+
+	min, max Vector2
+	frames   int
+
+	previous *image.Paletted
+	gif      gif.GIF
+}
+
+// Parses a comma-separated list of six hex colors (see defaultPalette).
+func parsePalette(text string) (color.Palette, error) {
+	parts := strings.Split(text, ",")
+	if len(parts) != 6 {
+		return nil, fmt.Errorf("palette: expected 6 colors, got %d", len(parts))
+	}
+
+	var palette color.Palette
+	for _, part := range parts {
+		part = strings.TrimPrefix(strings.TrimSpace(part), "#")
+		value, err := strconv.ParseUint(part, 16, 32)
+		if err != nil || len(part) != 6 {
+			return nil, fmt.Errorf("palette: invalid color %q", part)
+		}
+		palette = append(palette, color.RGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 0xff})
+	}
+
+	return palette, nil
+}
+
+// Every skip-th frame is added to the animation and each frame is shown for
+// delay hundredths of a second.
+func makeAnimation(palette color.Palette, scale, skip, delay int) *Animation {
+	return &Animation{
+		palette: palette,
+		scale:   max(scale, 1),
+		skip:    max(skip, 1),
+		delay:   delay,
+	}
+}
+
+func (animation *Animation) add(cabinet *Cabinet) {
+	animation.frames++
+	if (animation.frames-1)%animation.skip != 0 {
+		return
+	}
+	animation.render(cabinet)
+}
+
+func (animation *Animation) render(cabinet *Cabinet) {
+	if animation.previous == nil {
+		// The screen does not change size during the game.
+		for pos := range cabinet.grid {
+			animation.min = animation.min.Min(pos)
+			animation.max = animation.max.Max(pos)
+		}
+	}
+
+	scale := animation.scale
+	fontScale := max(scale/2, 1)
+	header := 7 * fontScale
+
+	width := (animation.max.x - animation.min.x + 1) * scale
+	height := (animation.max.y-animation.min.y+1)*scale + header
+
+	frame := image.NewPaletted(image.Rect(0, 0, width, height), animation.palette)
+
+	fill := func(x, y, size int, index uint8) {
+		for dy := 0; dy < size; dy++ {
+			for dx := 0; dx < size; dx++ {
+				frame.SetColorIndex(x+dx, y+dy, index)
+			}
+		}
+	}
+
+	for pos, tile := range cabinet.grid {
+		if tile >= 0 && tile <= Ball {
+			x := (pos.x - animation.min.x) * scale
+			y := (pos.y-animation.min.y)*scale + header
+			fill(x, y, scale, uint8(tile))
+		}
+	}
+
+	for index, digit := range strconv.FormatInt(cabinet.score, 10) {
+		for row, line := range digits[digit-'0'] {
+			for column, pixel := range line {
+				if pixel == '#' {
+					x := (1 + 4*index + column) * fontScale
+					y := (1 + row) * fontScale
+					fill(x, y, fontScale, uint8(len(animation.palette)-1))
+				}
+			}
+		}
+	}
+
+	animation.append(frame)
+}
+
+func (animation *Animation) append(frame *image.Paletted) {
+	previous := animation.previous
+	animation.previous = frame
+
+	if previous == nil {
+		animation.gif.Image = append(animation.gif.Image, frame)
+		animation.gif.Delay = append(animation.gif.Delay, animation.delay)
+		animation.gif.Disposal = append(animation.gif.Disposal, gif.DisposalNone)
+		return
+	}
+
+	// Find the bounding box of the pixels that changed.
+	var changed image.Rectangle
+	bounds := frame.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if frame.ColorIndexAt(x, y) != previous.ColorIndexAt(x, y) {
+				changed = changed.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	if changed.Empty() {
+		animation.gif.Delay[len(animation.gif.Delay)-1] += animation.delay
+		return
+	}
+
+	animation.gif.Image = append(animation.gif.Image, frame.SubImage(changed).(*image.Paletted))
+	animation.gif.Delay = append(animation.gif.Delay, animation.delay)
+	animation.gif.Disposal = append(animation.gif.Disposal, gif.DisposalNone)
+}
+
+// Adds the final state of the game and writes the animation to the file.
+func (animation *Animation) save(cabinet *Cabinet, filename string) error {
+	animation.render(cabinet)
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := gif.EncodeAll(file, &animation.gif); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
@@ -1,11 +1,16 @@
-package main
+package day13
 
 import (
+	"bufio"
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+	"image"
+	"image/color"
+	"io"
+	"os"
+
+	"greenlightning.eu/aoc19/intcode"
+	"greenlightning.eu/aoc19/render"
 )
 
 const (
@@ -16,299 +21,279 @@ const (
 	Ball   = 4
 )
 
-// Warning: For my input, this outputs about 150k lines.
-var printFlag = flag.Bool("print", false, "print game state before each input is provided")
+var Flags = flag.NewFlagSet("day13", flag.ContinueOnError)
 
-func main() {
-	flag.Parse()
+var (
+	// Warning: For my input, this outputs about 150k lines.
+	printFlag  = Flags.Bool("print", false, "print game state before each input is provided")
+	renderFlag = Flags.String("render", "", "render the final screen of the game to `file` (.png, .svg, .ans or text; - for the terminal)")
 
-	input := readFile("input.txt")
+	playFlag = Flags.Bool("play", false, "play the game yourself in the terminal")
+	fpsFlag  = Flags.Int("fps", 10, "frames per second in play mode")
 
-	var program []int64
-	for _, value := range strings.Split(input, ",") {
-		program = append(program, toInt64(value))
-	}
+	serveFlag       = Flags.String("serve", "", "serve the game to TCP clients on `address` (e.g. localhost:1313)")
+	maxSessionsFlag = Flags.Int("max-sessions", 8, "maximum number of concurrent sessions when serving")
+	sessionLogsFlag = Flags.String("session-logs", "", "record the session of each client to a file in `directory`")
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(countBlocks(program))
-	}
+	gifFlag        = Flags.String("gif", "", "write an animation of the game played by the bot to `file`")
+	gifScaleFlag   = Flags.Int("gif-scale", 4, "size of a tile in the animation in pixels")
+	gifSkipFlag    = Flags.Int("gif-skip", 1, "only add every n-th frame to the animation")
+	gifDelayFlag   = Flags.Int("gif-delay", 2, "delay between frames of the animation in hundredths of a second")
+	gifPaletteFlag = Flags.String("gif-palette", defaultPalette, "comma-separated hex colors for empty, wall, block, paddle, ball and score")
 
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(emulateArcadeCabinet(program))
+	planFlag          = Flags.Bool("plan", false, "compare a bot that plans ahead using machine snapshots to the reactive bot")
+	planObjectiveFlag = Flags.String("plan-objective", "frames", "what the planning bot optimizes: frames or chains (of blocks hit between two paddle hits)")
+	planDepthFlag     = Flags.Int("plan-depth", 3, "number of paddle hits the planning bot looks ahead")
+)
+
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
+
+	if *playFlag {
+		check(play(program, *fpsFlag))
+		return
 	}
-}
 
-func countBlocks(program []int64) (count int) {
-	input := make(chan int64)
-	messages := make(chan Message)
+	if *serveFlag != "" {
+		// Insert quarters.
+		program[0] = 2
 
-	go emulate(program, input, messages)
+		server := &intcode.Server{
+			Program:     program,
+			Handler:     serveArcadeCabinet,
+			MaxSessions: *maxSessionsFlag,
+			LogDir:      *sessionLogsFlag,
+		}
+		check(server.ListenAndServe(*serveFlag))
+		return
+	}
 
-	grid := make(map[Vector2]int64)
+	partOne = fmt.Sprint(countBlocks(program))
+	partTwo = fmt.Sprint(emulateArcadeCabinet(program))
 
-	for {
-		message := <-messages
-		switch message.Kind {
-		case MessageOutput:
-			var pos Vector2
-			pos.x = int(message.Value)
-
-			message = <-messages
-			if message.Kind != MessageOutput {
-				panic("unexpected message")
-			}
-			pos.y = int(message.Value)
+	if *planFlag {
+		objective, err := parseObjective(*planObjectiveFlag)
+		check(err)
+		if *planDepthFlag < 1 {
+			panic("the planning bot has to look at least one hit ahead")
+		}
+		comparePlanner(program, &Planner{objective, *planDepthFlag})
+	}
 
-			message = <-messages
-			if message.Kind != MessageOutput {
-				panic("unexpected message")
-			}
-			grid[pos] = message.Value
+	return partOne, partTwo
+}
 
-		case MessageHalt:
-			for _, tile := range grid {
-				if tile == Block {
-					count++
-				}
-			}
-			return
+func countBlocks(program []int64) int {
+	cabinet := makeCabinet(intcode.MakeEmulator(program))
 
-		default:
-			panic("unexpected message")
-		}
+	if cabinet.update() {
+		panic("unexpected input request")
 	}
+
+	return cabinet.count(Block)
 }
 
 func emulateArcadeCabinet(program []int64) int64 {
 	// Insert quarters.
 	program[0] = 2
 
-	input := make(chan int64)
-	messages := make(chan Message)
+	cabinet := makeCabinet(intcode.MakeEmulator(program))
 
-	go emulate(program, input, messages)
+	var animation *Animation
+	if *gifFlag != "" {
+		palette, err := parsePalette(*gifPaletteFlag)
+		check(err)
+		animation = makeAnimation(palette, *gifScaleFlag, *gifSkipFlag, *gifDelayFlag)
+	}
 
-	grid := make(map[Vector2]int64)
-	var score int64
+	for cabinet.update() {
+		if *printFlag {
+			cabinet.print(os.Stdout)
+		}
 
-	for {
-		message := <-messages
-		switch message.Kind {
-		case MessageWaitingForInput:
-			if *printFlag {
-				var min, max Vector2
-				for pos := range grid {
-					min = min.Min(pos)
-					max = max.Max(pos)
-				}
+		if animation != nil {
+			animation.add(cabinet)
+		}
 
-				for y := min.y; y <= max.y; y++ {
-					for x := min.x; x <= max.x; x++ {
-						switch grid[Vector2{x, y}] {
-						case Empty:
-							fmt.Print(" ")
-						case Wall:
-							fmt.Print("█")
-						case Block:
-							fmt.Print("X")
-						case Paddle:
-							fmt.Print("-")
-						case Ball:
-							fmt.Print("O")
-						}
-					}
-					fmt.Println()
-				}
-				fmt.Println("Score: ", score)
-			}
+		cabinet.emulator.Write(cabinet.track())
+	}
 
-			// Find the ball and the paddle, then move the paddle closer to the ball.
-			// Once they have the same x position, this will track the ball perfectly,
-			// since they both move at the same speed (1 tile / frame).
-			var ball, paddle Vector2
-			for pos, tile := range grid {
-				switch tile {
-				case Ball:
-					ball = pos
-				case Paddle:
-					paddle = pos
-				}
-			}
-			input <- int64(sign(ball.x - paddle.x))
+	if animation != nil {
+		check(animation.save(cabinet, *gifFlag))
+	}
 
-		case MessageOutput:
-			var pos Vector2
-			pos.x = int(message.Value)
+	if *renderFlag != "" {
+		check(render.WriteFile(*renderFlag, cabinet.screen(), screenStyle))
+	}
 
-			message = <-messages
-			if message.Kind != MessageOutput {
-				panic("unexpected message")
-			}
-			pos.y = int(message.Value)
+	return cabinet.score
+}
 
-			message = <-messages
-			if message.Kind != MessageOutput {
-				panic("unexpected message")
-			}
-			if pos.x == -1 && pos.y == 0 {
-				score = message.Value
-			} else {
-				grid[pos] = message.Value
-			}
+type Cabinet struct {
+	emulator *intcode.Emulator
+	grid     map[Vector2]int64
+	score    int64
 
-		case MessageHalt:
-			return score
+	// Positions of the ball and the paddle, which are recorded when their
+	// tiles are drawn.
+	ball, paddle Vector2
 
-		default:
-			panic("unexpected message")
-		}
+	// Number of times the game waited for input and number of blocks
+	// destroyed so far.
+	frames, destroyed int
+}
+
+func makeCabinet(emulator *intcode.Emulator) *Cabinet {
+	return &Cabinet{
+		emulator: emulator,
+		grid:     make(map[Vector2]int64),
 	}
 }
 
-// This version of the intcode emulator is synchronous, i.e. it sends a
-// MessageWaitingForInput before reading from the input channel. This makes it
-// possible to base the input on the previous output, without requiring the
-// controlling code to know the exact behavior of the intcode program. Using
-// channels in this way allows for a clean separation between the intcode
-// emulator and the puzzle specific controlling code.
+// Runs the game until it waits for the joystick position.
+// Returns false if the game is over instead.
+func (cabinet *Cabinet) update() bool {
+	for {
+		x, status := cabinet.emulator.Emulate()
+		switch status {
+		case intcode.EmulatorStatusWaitingForInput:
+			cabinet.frames++
+			return true
+		case intcode.EmulatorStatusHalted:
+			return false
+		}
+
+		y, status := cabinet.emulator.Emulate()
+		if status != intcode.EmulatorStatusOutput {
+			panic("unexpected status")
+		}
 
-const (
-	MessageWaitingForInput = iota
-	MessageOutput
-	MessageHalt
-)
+		tile, status := cabinet.emulator.Emulate()
+		if status != intcode.EmulatorStatusOutput {
+			panic("unexpected status")
+		}
 
-type Message struct {
-	Kind  int
-	Value int64
+		if x == -1 && y == 0 {
+			cabinet.score = tile
+		} else {
+			pos := Vector2{int(x), int(y)}
+			if cabinet.grid[pos] == Block && tile != Block {
+				cabinet.destroyed++
+			}
+			cabinet.grid[pos] = tile
+			switch tile {
+			case Ball:
+				cabinet.ball = pos
+			case Paddle:
+				cabinet.paddle = pos
+			}
+		}
+	}
 }
 
-func emulate(program []int64, input <-chan int64, messages chan<- Message) {
-	// Copy the program into memory, so that we do not modify the original.
-	memory := make([]int64, len(program))
-	copy(memory, program)
+// Returns an independent copy of the cabinet, including the state of the
+// machine, so that moves can be tried without affecting the original game.
+func (cabinet *Cabinet) clone() *Cabinet {
+	clone := *cabinet
+	clone.emulator = cabinet.emulator.Clone()
+	clone.grid = make(map[Vector2]int64, len(cabinet.grid))
+	for pos, tile := range cabinet.grid {
+		clone.grid[pos] = tile
+	}
+	return &clone
+}
 
-	getMemoryPointer := func(index int64) *int64 {
-		// Grow memory, if index is out of range.
-		for int64(len(memory)) <= index {
-			memory = append(memory, 0)
+func (cabinet *Cabinet) count(tile int64) (count int) {
+	for _, t := range cabinet.grid {
+		if t == tile {
+			count++
 		}
-		return &memory[index]
 	}
+	return
+}
 
-	var ip, relativeBase int64
-	for {
-		instruction := memory[ip]
-		opcode := instruction % 100
-
-		getParameter := func(offset int64) *int64 {
-			parameter := memory[ip+offset]
-			mode := instruction / pow(10, offset+1) % 10
-			switch mode {
-			case 0: // position mode
-				return getMemoryPointer(parameter)
-			case 1: // immediate mode
-				return &parameter
-			case 2: // relative mode
-				return getMemoryPointer(relativeBase + parameter)
-			default:
-				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", ip, instruction, offset, mode))
-			}
-		}
+// Move the paddle closer to the ball.
+// Once they have the same x position, this will track the ball perfectly,
+// since they both move at the same speed (1 tile / frame).
+func (cabinet *Cabinet) track() int64 {
+	return int64(sign(cabinet.ball.x - cabinet.paddle.x))
+}
 
-		switch opcode {
-
-		case 1: // ADD
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a + *b
-			ip += 4
-
-		case 2: // MULTIPLY
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a * *b
-			ip += 4
-
-		case 3: // INPUT
-			messages <- Message{Kind: MessageWaitingForInput}
-			a := getParameter(1)
-			*a = <-input
-			ip += 2
-
-		case 4: // OUTPUT
-			a := getParameter(1)
-			messages <- Message{Kind: MessageOutput, Value: *a}
-			ip += 2
-
-		case 5: // JUMP IF TRUE
-			a, b := getParameter(1), getParameter(2)
-			if *a != 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
+var screenStyle = render.Style{
+	Palette: map[int]render.Tile{
+		Empty:  {Glyph: ' ', Color: color.Black},
+		Wall:   {Glyph: '█', Color: color.RGBA{0x7f, 0x7f, 0x7f, 0xff}},
+		Block:  {Glyph: 'X', Color: color.RGBA{0xd9, 0x57, 0x63, 0xff}},
+		Paddle: {Glyph: '-', Color: color.RGBA{0x5b, 0x6e, 0xe1, 0xff}},
+		Ball:   {Glyph: 'O', Color: color.White},
+	},
+	Unknown: render.Tile{Glyph: ' ', Color: color.Black},
+}
 
-		case 6: // JUMP IF FALSE
-			a, b := getParameter(1), getParameter(2)
-			if *a == 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
+func (cabinet *Cabinet) screen() render.Sparse {
+	screen := make(render.Sparse)
+	for pos, tile := range cabinet.grid {
+		screen[image.Point{pos.x, pos.y}] = int(tile)
+	}
+	return screen
+}
 
-		case 7: // LESS THAN
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a < *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
-
-		case 8: // EQUAL
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a == *b {
-				*c = 1
-			} else {
-				*c = 0
+func (cabinet *Cabinet) print(w io.Writer) {
+	render.Text(w, cabinet.screen(), screenStyle)
+	fmt.Fprintln(w, "Score: ", cabinet.score)
+}
+
+// Serves one game over a connection, see the -serve flag. The screen is
+// redrawn each time the game waits for input. Each line received from the
+// client is a sequence of joystick moves, one per frame: "a" moves left, "d"
+// moves right and "s" (or an empty line) keeps the paddle in place.
+func serveArcadeCabinet(emulator *intcode.Emulator, client io.ReadWriter) error {
+	reader := bufio.NewReader(client)
+	writer := bufio.NewWriter(client)
+
+	cabinet := makeCabinet(emulator)
+
+	var moves []int64
+
+	for cabinet.update() {
+		if len(moves) == 0 {
+			// Clear the screen and move the cursor to the top left corner.
+			fmt.Fprint(writer, "\x1b[H\x1b[2J")
+			cabinet.print(writer)
+			fmt.Fprint(writer, "Joystick (a = left, s = stay, d = right)? ")
+			if err := writer.Flush(); err != nil {
+				return err
 			}
-			ip += 4
 
-		case 9: // RELATIVE BASE OFFSET
-			a := getParameter(1)
-			relativeBase += *a
-			ip += 2
+			line, err := intcode.ReadLine(reader)
+			if err != nil {
+				return err
+			}
 
-		case 99: // HALT
-			messages <- Message{Kind: MessageHalt}
-			return
+			for _, char := range line {
+				switch char {
+				case 'a', 'h', '<':
+					moves = append(moves, -1)
+				case 'd', 'l', '>':
+					moves = append(moves, 1)
+				case 's', 'j', '.', ' ':
+					moves = append(moves, 0)
+				}
+			}
 
-		default:
-			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", ip, instruction, opcode))
+			if len(moves) == 0 {
+				moves = append(moves, 0)
+			}
 		}
-	}
-}
 
-// Integer power: compute a**b using binary powering algorithm
-// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
-// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
-func pow(a, b int64) int64 {
-	var p int64 = 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
-		}
-		b >>= 1
-		a *= a
+		cabinet.emulator.Write(moves[0])
+		moves = moves[1:]
 	}
-	return p
-}
 
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
+	cabinet.print(writer)
+	fmt.Fprintln(writer, "Game over!")
+	return writer.Flush()
 }
 
 type Vector2 struct {
@@ -329,12 +314,6 @@ func (v Vector2) Max(other Vector2) Vector2 {
 	}
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -351,6 +330,13 @@ func sign(x int) int {
 	return 0
 }
 
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
 func min(x, y int) int {
 	if y < x {
 		return y
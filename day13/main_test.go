@@ -0,0 +1,151 @@
+package day13
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"greenlightning.eu/aoc19/aoctest"
+	"greenlightning.eu/aoc19/intcode"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestCabinet(t *testing.T) {
+	program := []int64{
+		// The paddle and the ball from the puzzle statement, two blocks
+		// and the score.
+		104, 1, 104, 2, 104, Paddle,
+		104, 6, 104, 5, 104, Ball,
+		104, 2, 104, 0, 104, Block,
+		104, 3, 104, 0, 104, Block,
+		104, -1, 104, 0, 104, 12345,
+		// Wait for the joystick, then destroy the first block.
+		3, 100,
+		104, 2, 104, 0, 104, Empty,
+		99,
+	}
+	cabinet := makeCabinet(intcode.MakeEmulator(program))
+
+	if !cabinet.update() {
+		t.Fatal("the game ended before the first frame")
+	}
+	if cabinet.paddle != (Vector2{1, 2}) || cabinet.ball != (Vector2{6, 5}) {
+		t.Errorf("paddle at %v and ball at %v, want {1 2} and {6 5}", cabinet.paddle, cabinet.ball)
+	}
+	if cabinet.score != 12345 {
+		t.Errorf("score %d, want 12345", cabinet.score)
+	}
+	if got := cabinet.count(Block); got != 2 {
+		t.Errorf("%d blocks, want 2", got)
+	}
+	if got := cabinet.track(); got != 1 {
+		t.Errorf("moved the joystick to %d, want 1", got)
+	}
+
+	cabinet.emulator.Write(cabinet.track())
+	if cabinet.update() {
+		t.Fatal("the game did not end")
+	}
+	if cabinet.frames != 1 || cabinet.destroyed != 1 {
+		t.Errorf("%d frames and %d destroyed blocks, want 1 and 1", cabinet.frames, cabinet.destroyed)
+	}
+}
+
+func TestParsePalette(t *testing.T) {
+	palette, err := parsePalette(defaultPalette)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(palette) != 6 {
+		t.Errorf("%d colors, want 6", len(palette))
+	}
+
+	for _, text := range []string{"000000", "000000,ffffff,000000,ffffff,000000,fffff", "000000,ffffff,000000,ffffff,000000,gggggg"} {
+		if _, err := parsePalette(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
+
+func TestOutcomeBetter(t *testing.T) {
+	tests := []struct {
+		a, b      Outcome
+		objective Objective
+		better    bool
+	}{
+		{Outcome{Frames: 100, Blocks: 1}, Outcome{Frames: 10, Blocks: 5, Lost: true}, ObjectiveFrames, true},
+		{Outcome{Frames: 10, Blocks: 2}, Outcome{Frames: 10, Blocks: 1}, ObjectiveFrames, true},
+		{Outcome{Frames: 10, Blocks: 2}, Outcome{Frames: 5, Blocks: 1}, ObjectiveFrames, false},
+		{Outcome{Frames: 20, Blocks: 2, Over: true}, Outcome{Frames: 30, Blocks: 9, Over: true}, ObjectiveFrames, true},
+		{Outcome{Frames: 10, Blocks: 4, Chain: 1}, Outcome{Frames: 10, Blocks: 2, Chain: 2}, ObjectiveChains, false},
+	}
+
+	for _, test := range tests {
+		if got := test.a.better(test.b, test.objective); got != test.better {
+			t.Errorf("%+v better than %+v: got %v, want %v", test.a, test.b, got, test.better)
+		}
+	}
+}
+
+// Returns one chunk per read, like a terminal in raw mode, and an empty
+// chunk when the read times out.
+type terminal struct {
+	chunks []string
+}
+
+func (t *terminal) Read(p []byte) (int, error) {
+	if len(t.chunks) == 0 {
+		time.Sleep(time.Millisecond)
+		return 0, io.EOF
+	}
+	chunk := t.chunks[0]
+	t.chunks = t.chunks[1:]
+	if chunk == "" {
+		return 0, io.EOF
+	}
+	return copy(p, chunk), nil
+}
+
+func TestReadKeys(t *testing.T) {
+	input := &terminal{chunks: []string{
+		"\x1b[D", "\x1b[C",
+		// A lone escape key, once followed by a timeout and once by
+		// another key.
+		"\x1b", "", "\x1bb",
+		"x", "q",
+	}}
+	want := []Key{KeyLeft, KeyRight, KeyOther, KeyOther, KeyAssist, KeyOther, KeyQuit}
+
+	keys := make(chan Key)
+	done := make(chan bool)
+	stopped := make(chan bool)
+	go func() {
+		readKeys(input, keys, done)
+		close(stopped)
+	}()
+
+	for index, want := range want {
+		select {
+		case key := <-keys:
+			if key != want {
+				t.Errorf("key %d: got %d, want %d", index, key, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("key %d: timed out", index)
+		}
+	}
+
+	close(done)
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Error("the reader did not stop")
+	}
+}
@@ -0,0 +1,268 @@
+package day13
+
+import (
+	"fmt"
+
+	"greenlightning.eu/aoc19/intcode"
+)
+
+// Planning bot (see -plan flag). The ball moves independently of the paddle,
+// until it reaches the row above the paddle. If the paddle is directly below
+// the ball at that moment, the ball keeps its horizontal direction. If the
+// paddle is one tile ahead of the ball, the ball bounces back the way it came.
+// The planner forks the machine to find out where the ball will reach the
+// paddle row next and tries both ways of hitting it, recursively up to a
+// given number of hits ahead.
+
+type Objective int
+
+const (
+	// Destroy as many blocks per frame as possible.
+	ObjectiveFrames Objective = 0
+	// Destroy as many blocks as possible before the ball returns to the
+	// paddle.
+	ObjectiveChains Objective = 1
+)
+
+func parseObjective(name string) (Objective, error) {
+	switch name {
+	case "frames":
+		return ObjectiveFrames, nil
+	case "chains":
+		return ObjectiveChains, nil
+	default:
+		return 0, fmt.Errorf("unknown objective: %q (expected frames or chains)", name)
+	}
+}
+
+// The outcome of a sequence of segments, where a segment is the part of the
+// game between two hits of the ball.
+type Outcome struct {
+	Frames, Blocks int
+
+	// Largest number of blocks destroyed in a single segment.
+	Chain int
+
+	// Whether the game ended, because all blocks were destroyed or because
+	// the paddle missed the ball.
+	Over, Lost bool
+}
+
+func (outcome Outcome) then(next Outcome) Outcome {
+	return Outcome{
+		Frames: outcome.Frames + next.Frames,
+		Blocks: outcome.Blocks + next.Blocks,
+		Chain:  max(outcome.Chain, next.Chain),
+		Over:   next.Over,
+		Lost:   next.Lost,
+	}
+}
+
+func (outcome Outcome) better(other Outcome, objective Objective) bool {
+	if outcome.Lost != other.Lost {
+		return !outcome.Lost
+	}
+
+	if objective == ObjectiveChains && outcome.Chain != other.Chain {
+		return outcome.Chain > other.Chain
+	}
+
+	if outcome.Over && other.Over {
+		return outcome.Frames < other.Frames
+	}
+
+	// Compare blocks per frame without dividing.
+	a, b := outcome.Blocks*other.Frames, other.Blocks*outcome.Frames
+	if a != b {
+		return a > b
+	}
+	return outcome.Frames < other.Frames
+}
+
+type Planner struct {
+	objective Objective
+	depth     int
+}
+
+// Returns where the paddle should be when the ball reaches the paddle row
+// next, starting from a state in which the ball has just been hit (or the
+// start of the game). The target is -1 if the game ends before that.
+func (planner *Planner) plan(cabinet *Cabinet, depth int) (target int, outcome Outcome) {
+	start := cabinet.paddle
+
+	// The paddle has to stay in place while the ball bounces off it.
+	moves := func(frames int) int {
+		if cabinet.ball.y == start.y-1 {
+			return frames - 1
+		}
+		return frames
+	}
+
+	// Keep the paddle in place to find out where the ball goes. This also
+	// checks that the paddle actually hit the ball.
+	fork := cabinet.clone()
+	previous := fork.ball
+	for {
+		fork.emulator.Write(0)
+		if !fork.update() {
+			remaining := fork.count(Block)
+			return -1, Outcome{
+				Frames: fork.frames - cabinet.frames,
+				Blocks: fork.destroyed - cabinet.destroyed,
+				Chain:  fork.destroyed - cabinet.destroyed,
+				Over:   true,
+				Lost:   remaining > 0,
+			}
+		}
+
+		ball := fork.ball
+		if ball.y == start.y-1 {
+			break
+		}
+		previous = ball
+	}
+
+	ball := fork.ball
+	frames := fork.frames - cabinet.frames
+	segment := Outcome{
+		Frames: frames,
+		Blocks: fork.destroyed - cabinet.destroyed,
+		Chain:  fork.destroyed - cabinet.destroyed,
+	}
+
+	// The paddle can either hit the ball directly or one tile ahead of it.
+	var targets []int
+	for _, x := range []int{ball.x, ball.x + (ball.x - previous.x)} {
+		if abs(x-start.x) <= moves(frames) && fork.grid[Vector2{x, start.y}] != Wall {
+			targets = append(targets, x)
+		}
+	}
+
+	if len(targets) == 0 {
+		segment.Over, segment.Lost = true, true
+		return ball.x, segment
+	}
+
+	if depth == 0 {
+		return targets[0], segment
+	}
+
+	first := true
+	for _, x := range targets {
+		branch := cabinet.clone()
+		for branch.frames < fork.frames {
+			move := sign(x - branch.paddle.x)
+			if fork.frames-branch.frames > moves(frames) {
+				move = 0
+			}
+			branch.emulator.Write(int64(move))
+			if !branch.update() {
+				break
+			}
+		}
+		if branch.frames < fork.frames {
+			continue
+		}
+
+		_, next := planner.plan(branch, depth-1)
+		candidate := segment.then(next)
+		if first || candidate.better(outcome, planner.objective) {
+			target, outcome = x, candidate
+			first = false
+		}
+	}
+
+	return target, outcome
+}
+
+// Statistics of a whole game.
+type GameStats struct {
+	Frames, Chain int
+	Score         int64
+}
+
+// Called each frame to keep track of the chains.
+type chainCounter struct {
+	last, longest int
+}
+
+func (counter *chainCounter) observe(cabinet *Cabinet) {
+	if cabinet.ball.y == cabinet.paddle.y-1 {
+		counter.finish(cabinet)
+	}
+}
+
+func (counter *chainCounter) finish(cabinet *Cabinet) {
+	counter.longest = max(counter.longest, cabinet.destroyed-counter.last)
+	counter.last = cabinet.destroyed
+}
+
+// Plays the game with the reactive bot (see Cabinet.track).
+func playReactive(program []int64) GameStats {
+	cabinet := makeCabinet(intcode.MakeEmulator(program))
+	var chains chainCounter
+
+	for cabinet.update() {
+		chains.observe(cabinet)
+		cabinet.emulator.Write(cabinet.track())
+	}
+	chains.finish(cabinet)
+
+	return GameStats{cabinet.frames, chains.longest, cabinet.score}
+}
+
+func playPlanned(program []int64, planner *Planner) GameStats {
+	cabinet := makeCabinet(intcode.MakeEmulator(program))
+	var chains chainCounter
+
+	if !cabinet.update() {
+		panic("unexpected halt")
+	}
+	row := cabinet.paddle.y
+
+	for {
+		target, _ := planner.plan(cabinet, planner.depth)
+		if target < 0 {
+			// The game ends before the ball comes back.
+			target = cabinet.paddle.x
+		}
+
+		for {
+			move := sign(target - cabinet.paddle.x)
+			if cabinet.ball.y == row-1 {
+				// The paddle has to stay in place while the ball bounces off
+				// it.
+				move = 0
+			}
+			cabinet.emulator.Write(int64(move))
+
+			if !cabinet.update() {
+				chains.finish(cabinet)
+				if cabinet.count(Block) != 0 {
+					panic("the planner missed the ball")
+				}
+				return GameStats{cabinet.frames, chains.longest, cabinet.score}
+			}
+
+			if cabinet.ball.y == row-1 {
+				chains.finish(cabinet)
+				break
+			}
+		}
+	}
+}
+
+func comparePlanner(program []int64, planner *Planner) {
+	// Insert quarters.
+	program[0] = 2
+
+	reactive := playReactive(program)
+	planned := playPlanned(program, planner)
+
+	fmt.Println("----------------")
+	fmt.Println("Bot        Frames  Longest chain  Score")
+	fmt.Printf("%-8s %8d %14d %6d\n", "reactive", reactive.Frames, reactive.Chain, reactive.Score)
+	fmt.Printf("%-8s %8d %14d %6d\n", "planner", planned.Frames, planned.Chain, planned.Score)
+	fmt.Printf("The planner needed %d frames (%+.1f%%) compared to the reactive bot.\n",
+		planned.Frames, 100*float64(planned.Frames-reactive.Frames)/float64(reactive.Frames))
+}
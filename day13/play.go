@@ -0,0 +1,263 @@
+package day13
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"greenlightning.eu/aoc19/intcode"
+)
+
+// Interactive play mode (see -play flag). The terminal is put into raw mode,
+// so that single key presses can be read, and the screen is redrawn in place
+// using ANSI escape sequences.
+
+type Key int
+
+const (
+	KeyLeft   Key = 0
+	KeyRight  Key = 1
+	KeyStay   Key = 2
+	KeyAssist Key = 3
+	KeyFaster Key = 4
+	KeySlower Key = 5
+	KeyQuit   Key = 6
+
+	// Any other key, which only ends the game after it is over.
+	KeyOther Key = 7
+)
+
+func play(program []int64, fps int) error {
+	if fps < 1 {
+		return fmt.Errorf("-fps must be at least 1, got %d", fps)
+	}
+
+	restore, err := makeRaw()
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	// Stop the reader before the terminal is restored, because its reads
+	// only time out in raw mode.
+	keys := make(chan Key)
+	done := make(chan bool)
+	stopped := make(chan bool)
+	go func() {
+		readKeys(os.Stdin, keys, done)
+		close(stopped)
+	}()
+	defer func() {
+		close(done)
+		<-stopped
+	}()
+
+	// Insert quarters.
+	program[0] = 2
+
+	cabinet := makeCabinet(intcode.MakeEmulator(program))
+	assist := false
+
+	// Clear the screen and hide the cursor.
+	fmt.Print("\x1b[2J\x1b[?25l")
+	defer fmt.Print("\x1b[?25h\r\n")
+
+	ticker := time.NewTicker(time.Second / time.Duration(fps))
+	defer func() { ticker.Stop() }()
+
+	for cabinet.update() {
+		draw(cabinet, fmt.Sprintf("Bot: %s  FPS: %d  (←/→ move, b bot, +/- speed, q quit)", onOff(assist), fps))
+
+		// The last key pressed during a frame determines the joystick
+		// position for the next frame. The terminal does not report when a
+		// key is released, so the paddle only keeps moving while the key is
+		// repeated.
+		var move int64
+		moved := false
+
+	frame:
+		for {
+			select {
+			case key := <-keys:
+				switch key {
+				case KeyLeft:
+					move, moved = -1, true
+				case KeyRight:
+					move, moved = 1, true
+				case KeyStay:
+					move, moved = 0, true
+				case KeyAssist:
+					assist = !assist
+				case KeyFaster, KeySlower:
+					if key == KeyFaster {
+						fps = min(fps*2, 240)
+					} else {
+						fps = max(fps/2, 1)
+					}
+					ticker.Stop()
+					ticker = time.NewTicker(time.Second / time.Duration(fps))
+				case KeyQuit:
+					return nil
+				}
+
+			case <-ticker.C:
+				break frame
+			}
+		}
+
+		if !moved && assist {
+			move = cabinet.track()
+		}
+
+		cabinet.emulator.Write(move)
+	}
+
+	draw(cabinet, "Game over! Press any key to exit.")
+	<-keys
+	return nil
+}
+
+func draw(cabinet *Cabinet, status string) {
+	var buffer bytes.Buffer
+
+	// Move the cursor to the top left corner.
+	buffer.WriteString("\x1b[H")
+	cabinet.print(&buffer)
+	buffer.WriteString(status)
+
+	// Clear the rest of the line, in case the status got shorter.
+	buffer.WriteString("\x1b[K")
+
+	// In raw mode, a line feed does not return the cursor to the start of
+	// the line.
+	fmt.Print(strings.Replace(buffer.String(), "\n", "\r\n", -1))
+}
+
+func onOff(value bool) string {
+	if value {
+		return "on"
+	}
+	return "off"
+}
+
+// Sends the keys read from r until done is closed. Reads from the terminal
+// time out after a tenth of a second without input (see makeRaw), which
+// shows up as io.EOF. This allows the reader to stop and to tell a lone
+// escape key from an escape sequence, whose bytes arrive together.
+func readKeys(r io.Reader, keys chan<- Key, done <-chan bool) {
+	var pending []byte
+	buffer := make([]byte, 64)
+
+	// Returns false if the read timed out.
+	next := func() (byte, bool, error) {
+		if len(pending) == 0 {
+			n, err := r.Read(buffer)
+			pending = append(pending, buffer[:n]...)
+			if n == 0 {
+				if err == io.EOF {
+					err = nil
+				}
+				return 0, false, err
+			}
+		}
+		char := pending[0]
+		pending = pending[1:]
+		return char, true, nil
+	}
+
+	send := func(key Key) bool {
+		select {
+		case keys <- key:
+			return true
+		case <-done:
+			return false
+		}
+	}
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		char, ok, err := next()
+		if err != nil {
+			send(KeyQuit)
+			return
+		}
+		if !ok {
+			continue
+		}
+
+		key := KeyOther
+		switch char {
+		case 'a', 'h':
+			key = KeyLeft
+		case 'd', 'l':
+			key = KeyRight
+		case 's', 'j', ' ':
+			key = KeyStay
+		case 'b':
+			key = KeyAssist
+		case '+', '=':
+			key = KeyFaster
+		case '-':
+			key = KeySlower
+		case 'q', 3, 4: // Ctrl-C and Ctrl-D are not handled by the terminal in raw mode.
+			key = KeyQuit
+
+		case 0x1b:
+			// Arrow keys are sent as escape sequences.
+			second, ok, _ := next()
+			if ok && second == '[' {
+				code, _, _ := next()
+				switch code {
+				case 'D':
+					key = KeyLeft
+				case 'C':
+					key = KeyRight
+				case 'B':
+					key = KeyStay
+				}
+			} else if ok {
+				// A lone escape key followed by another key.
+				pending = append([]byte{second}, pending...)
+			}
+		}
+
+		if !send(key) {
+			return
+		}
+	}
+}
+
+// Puts the terminal into raw mode using stty and returns a function that
+// restores the previous state.
+func makeRaw() (restore func(), err error) {
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("play mode requires a terminal: %v", err)
+	}
+
+	// With min 0 and time 1, a read returns after a tenth of a second even
+	// if there is no input (see readKeys).
+	if _, err := stty("raw", "-echo", "min", "0", "time", "1"); err != nil {
+		return nil, err
+	}
+
+	return func() {
+		stty(strings.TrimSpace(state))
+	}, nil
+}
+
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	output, err := cmd.Output()
+	return string(output), err
+}
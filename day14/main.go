@@ -1,12 +1,10 @@
-package main
+package day14
 
 import (
-	"bufio"
 	"fmt"
-	"os"
-	"regexp"
-	"strconv"
 	"strings"
+
+	"greenlightning.eu/aoc19/parse"
 )
 
 type Part struct {
@@ -19,44 +17,47 @@ type Reaction struct {
 	Output Part
 }
 
-func main() {
-	lines := readLines("input.txt")
+var (
+	reactionPattern = parse.MustCompile(`(\d+ \w+(?:, \d+ \w+)*) => (\d+ \w+)`)
+	partPattern     = parse.MustCompile(`(\d+) (\w+)`)
+)
 
+func Solve(filename string) (partOne, partTwo string) {
 	reactions := make(map[string]Reaction)
 
-	reactionRegex := regexp.MustCompile(`^(\d+ \w+(?:, \d+ \w+)*) => (\d+ \w+)$`)
-	partRegex := regexp.MustCompile(`^(\d+) (\w+)$`)
-
-	parsePart := func(text string) (part Part) {
-		match := partRegex.FindStringSubmatch(text)
-		part.Quantity = toInt(match[1])
-		part.Name = match[2]
-		return
-	}
+	err := parse.Lines(filename, func(line string) error {
+		var inputs, output string
+		if err := reactionPattern.Scan(line, &inputs, &output); err != nil {
+			return err
+		}
 
-	for _, line := range lines {
-		match := reactionRegex.FindStringSubmatch(line)
-		inputs, output := match[1], match[2]
 		var reaction Reaction
-		for _, input := range strings.Split(inputs, ", ") {
-			reaction.Inputs = append(reaction.Inputs, parsePart(input))
+		for _, text := range strings.Split(inputs, ", ") {
+			var input Part
+			if err := partPattern.Scan(text, &input.Quantity, &input.Name); err != nil {
+				return err
+			}
+			reaction.Inputs = append(reaction.Inputs, input)
+		}
+		if err := partPattern.Scan(output, &reaction.Output.Quantity, &reaction.Output.Name); err != nil {
+			return err
 		}
-		reaction.Output = parsePart(output)
+
 		reactions[reaction.Output.Name] = reaction
-	}
+		return nil
+	})
+	check(err)
 
 	var oreRequiredForOneFuel int
 
 	{
-		fmt.Println("--- Part One ---")
 		required := map[string]int{"FUEL": 1}
 		reduce(required, reactions)
 		oreRequiredForOneFuel = required["ORE"]
-		fmt.Println(oreRequiredForOneFuel)
+		partOne = fmt.Sprint(oreRequiredForOneFuel)
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
 		availableOre := 1_000_000_000_000
 		fuel, step := 0, availableOre/oreRequiredForOneFuel
 		required := make(map[string]int)
@@ -85,8 +86,10 @@ func main() {
 			// We cannot make one more fuel.
 			break
 		}
-		fmt.Println(fuel)
+		partTwo = fmt.Sprint(fuel)
 	}
+
+	return partOne, partTwo
 }
 
 func reduce(required map[string]int, reactions map[string]Reaction) {
@@ -112,26 +115,6 @@ func reduce(required map[string]int, reactions map[string]Reaction) {
 	}
 }
 
-func readLines(filename string) []string {
-	file, err := os.Open(filename)
-	check(err)
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	return lines
-}
-
-func toInt(s string) int {
-	result, err := strconv.Atoi(s)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -1,11 +1,15 @@
-package main
+package day15
 
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+	"image"
+	"image/color"
+	"os"
+
+	"greenlightning.eu/aoc19/intcode"
+	"greenlightning.eu/aoc19/render"
+	"greenlightning.eu/aoc19/search"
 )
 
 const (
@@ -13,50 +17,47 @@ const (
 	Path = 1
 )
 
-const (
-	North = 1
-	South = 2
-	West  = 3
-	East  = 4
-)
-
-var (
-	Up    = Vector2{0, -1}
-	Down  = Vector2{0, 1}
-	Left  = Vector2{-1, 0}
-	Right = Vector2{1, 0}
-)
-
-var (
-	commands   = []int64{North, South, West, East}
-	directions = []Vector2{Up, Down, Left, Right}
-	reverse    = map[int64]int64{North: South, South: North, West: East, East: West}
-	direction  = map[int64]Vector2{North: Up, South: Down, West: Left, East: Right}
-)
-
 type QueueItem struct {
 	Position Vector2
 	Distance int
-	Next     *QueueItem
 }
 
-var printFlag = flag.Bool("print", false, "print map of the discovered area")
+var Flags = flag.NewFlagSet("day15", flag.ContinueOnError)
 
-func main() {
-	flag.Parse()
+var (
+	printFlag  = Flags.Bool("print", false, "print map of the discovered area")
+	renderFlag = Flags.String("render", "", "render map of the discovered area to `file` (.png, .svg, .ans or text; - for the terminal)")
+	recordFlag = Flags.String("record", "", "record the session of the repair droid to `file`")
+	replayFlag = Flags.String("replay", "", "replay the session of the repair droid from `file`")
+)
 
-	text := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
 
-	var program []int64
-	for _, value := range strings.Split(text, ",") {
-		program = append(program, toInt64(value))
+	if *replayFlag != "" {
+		session, err := intcode.LoadSession(*replayFlag)
+		check(err)
+		check(intcode.Replay(program, session))
+		fmt.Printf("Replayed %d events.\n", len(session.Events))
+		return
+	}
+
+	emulator := intcode.MakeEmulator(program)
+
+	if *recordFlag != "" {
+		session := new(intcode.Session)
+		emulator.Record(session)
+		defer func() {
+			check(intcode.SaveSession(*recordFlag, session))
+		}()
 	}
 
 	input := make(chan int64)
 	output := make(chan int64)
 	halt := make(chan bool)
 
-	go emulate(program, input, output, halt)
+	go intcode.Run(emulator, input, output, halt)
 
 	var pos Vector2
 
@@ -77,11 +78,11 @@ func main() {
 
 			pos = navigate(pos, item.Position, grid, input, output)
 
-			for _, cmd := range commands {
-				next, nextDistance := pos.Plus(direction[cmd]), item.Distance+1
+			for _, dir := range Directions4 {
+				next, nextDistance := pos.Move(dir), item.Distance+1
 				if _, ok := grid[next]; !ok {
 					// Try command if we do not know what lies in this direction.
-					input <- cmd
+					input <- dir.Command()
 					switch <-output {
 					case 0:
 						grid[next] = Wall
@@ -95,7 +96,7 @@ func main() {
 						grid[next] = Path
 						queue = append(queue, QueueItem{Position: next, Distance: nextDistance})
 						// Command succeeded, go back to try other commands.
-						input <- reverse[cmd]
+						input <- dir.Reverse().Command()
 						<-output
 					}
 				}
@@ -103,287 +104,204 @@ func main() {
 		}
 	}
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(oxygenDistance)
-	}
-
-	var maxDistance int
-
-	// Fill complete map and record maximum distance.
-	{
-		var queue []QueueItem
-		queue = append(queue, QueueItem{Position: oxygenPos, Distance: 0})
-
-		visited := make(map[Vector2]bool)
-		visited[oxygenPos] = true
+	partOne = fmt.Sprint(oxygenDistance)
 
-		for len(queue) != 0 {
-			item := queue[0]
-			queue = queue[1:]
-
-			maxDistance = max(maxDistance, item.Distance)
+	partTwo = fmt.Sprint(fillTime(grid, oxygenPos))
 
-			for _, dir := range directions {
-				next := item.Position.Plus(dir)
-				if !visited[next] && grid[next] == Path {
-					visited[next] = true
-					queue = append(queue, QueueItem{Position: next, Distance: item.Distance + 1})
-				}
-			}
+	if *printFlag || *renderFlag != "" {
+		area := make(render.Sparse)
+		for pos, value := range grid {
+			area[image.Point{pos.x, pos.y}] = value
 		}
-	}
 
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(maxDistance)
-	}
-
-	if *printFlag {
-		fmt.Println("----------------")
+		style := render.Style{
+			Palette: map[int]render.Tile{
+				Wall: {Glyph: '#', Color: color.RGBA{0x60, 0x60, 0x60, 0xff}},
+				Path: {Glyph: '.', Color: color.Black},
+			},
+			Unknown: render.Tile{Glyph: '?'},
+		}
+		style.Highlight(image.Point{0, 0}, render.Tile{Glyph: 'S', Color: color.RGBA{0x40, 0x80, 0xff, 0xff}})
+		style.Highlight(image.Point{oxygenPos.x, oxygenPos.y}, render.Tile{Glyph: 'O', Color: color.RGBA{0x40, 0xff, 0x80, 0xff}})
 
-		var min, max Vector2
-		for pos := range grid {
-			min = min.Min(pos)
-			max = max.Max(pos)
+		if *printFlag {
+			fmt.Println("----------------")
+			check(render.Text(os.Stdout, area, style))
+		}
+		if *renderFlag != "" {
+			check(render.WriteFile(*renderFlag, area, style))
 		}
+	}
+	return partOne, partTwo
+}
 
-		for y := min.y; y <= max.y; y++ {
-			for x := min.x; x <= max.x; x++ {
-				pos := Vector2{x, y}
-				value, ok := grid[pos]
-				if x == 0 && y == 0 {
-					fmt.Print("S")
-				} else if pos == oxygenPos {
-					fmt.Print("O")
-				} else if !ok {
-					fmt.Print("?")
-				} else if value == Path {
-					fmt.Print(".")
-				} else {
-					fmt.Print("#")
-				}
+// Returns the neighbors of a position that are known paths in grid.
+func pathNeighbors(grid map[Vector2]int) func(node search.Node) []search.Node {
+	return func(node search.Node) []search.Node {
+		var result []search.Node
+		for _, next := range node.(Vector2).Neighbors4() {
+			if grid[next] == Path {
+				result = append(result, next)
 			}
-			fmt.Println()
 		}
+		return result
+	}
+}
+
+// Fill the complete map from the oxygen system and return the maximum distance.
+func fillTime(grid map[Vector2]int, oxygenPos Vector2) int {
+	var maxDistance int
+	result := search.BFS([]search.Node{oxygenPos}, pathNeighbors(grid), nil)
+	for _, distance := range result.Distances {
+		maxDistance = max(maxDistance, distance)
 	}
+	return maxDistance
 }
 
 // Move from pos to target following only known paths in grid.
 // Returns target (i.e. the new position after moving).
 func navigate(pos, target Vector2, grid map[Vector2]int, input chan int64, output chan int64) Vector2 {
-	var link *QueueItem
-
-	// Find shortest route from target to pos (note reversed order).
-	{
-		var queue []QueueItem
-		queue = append(queue, QueueItem{Position: target, Distance: 0})
-
-		visited := make(map[Vector2]bool)
-		visited[target] = true
+	result := search.BFS([]search.Node{pos}, pathNeighbors(grid), func(node search.Node) bool {
+		return node == target
+	})
+
+	// Follow the path and apply the correct commands.
+	path := result.Path(target)
+	for i := 1; i < len(path); i++ {
+		dir, _ := DirectionOf(path[i].(Vector2).Minus(path[i-1].(Vector2)))
+		input <- dir.Command()
+		<-output
+	}
 
-		for len(queue) != 0 {
-			item := queue[0]
-			queue = queue[1:]
+	return target
+}
 
-			if item.Position == pos {
-				link = &item
-				break
-			}
+type Vector2 struct {
+	x, y int
+}
 
-			for _, dir := range directions {
-				next := item.Position.Plus(dir)
-				if !visited[next] && grid[next] == Path {
-					visited[next] = true
-					queue = append(queue, QueueItem{Position: next, Distance: item.Distance + 1, Next: &item})
-				}
-			}
-		}
+func (v Vector2) Plus(other Vector2) Vector2 {
+	return Vector2{
+		x: v.x + other.x,
+		y: v.y + other.y,
 	}
+}
 
-	// Follow path backwards from pos to target and apply correct commands.
-	for link.Next != nil {
-		for cmd, dir := range direction {
-			if link.Position.Plus(dir) == link.Next.Position {
-				input <- cmd
-				<-output
-				break
-			}
-		}
-
-		link = link.Next
+func (v Vector2) Minus(other Vector2) Vector2 {
+	return Vector2{
+		x: v.x - other.x,
+		y: v.y - other.y,
 	}
-
-	return target
 }
 
-func emulate(program []int64, input <-chan int64, output chan<- int64, halt chan<- bool) {
-	// Copy the program into memory, so that we do not modify the original.
-	memory := make([]int64, len(program))
-	copy(memory, program)
+// Direction is one of the eight compass directions, in clockwise order. The
+// y axis points down, like on screen and in the puzzle inputs.
+type Direction int
 
-	getMemoryPointer := func(index int64) *int64 {
-		// Grow memory, if index is out of range.
-		for int64(len(memory)) <= index {
-			memory = append(memory, 0)
-		}
-		return &memory[index]
-	}
+const (
+	Up        Direction = 0
+	UpRight   Direction = 1
+	Right     Direction = 2
+	DownRight Direction = 3
+	Down      Direction = 4
+	DownLeft  Direction = 5
+	Left      Direction = 6
+	UpLeft    Direction = 7
+)
 
-	var ip, relativeBase int64
-	for {
-		instruction := memory[ip]
-		opcode := instruction % 100
-
-		getParameter := func(offset int64) *int64 {
-			parameter := memory[ip+offset]
-			mode := instruction / pow(10, offset+1) % 10
-			switch mode {
-			case 0: // position mode
-				return getMemoryPointer(parameter)
-			case 1: // immediate mode
-				return &parameter
-			case 2: // relative mode
-				return getMemoryPointer(relativeBase + parameter)
-			default:
-				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", ip, instruction, offset, mode))
-			}
-		}
+var (
+	Directions4 = []Direction{Up, Right, Down, Left}
+	Directions8 = []Direction{Up, UpRight, Right, DownRight, Down, DownLeft, Left, UpLeft}
+)
 
-		switch opcode {
-
-		case 1: // ADD
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a + *b
-			ip += 4
-
-		case 2: // MULTIPLY
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a * *b
-			ip += 4
-
-		case 3: // INPUT
-			a := getParameter(1)
-			*a = <-input
-			ip += 2
-
-		case 4: // OUTPUT
-			a := getParameter(1)
-			output <- *a
-			ip += 2
-
-		case 5: // JUMP IF TRUE
-			a, b := getParameter(1), getParameter(2)
-			if *a != 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
+var directionVectors = [8]Vector2{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
 
-		case 6: // JUMP IF FALSE
-			a, b := getParameter(1), getParameter(2)
-			if *a == 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
+func (d Direction) TurnLeft() Direction {
+	return (d + 6) % 8
+}
 
-		case 7: // LESS THAN
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a < *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
-
-		case 8: // EQUAL
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a == *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
+func (d Direction) TurnRight() Direction {
+	return (d + 2) % 8
+}
 
-		case 9: // RELATIVE BASE OFFSET
-			a := getParameter(1)
-			relativeBase += *a
-			ip += 2
+func (d Direction) Reverse() Direction {
+	return (d + 4) % 8
+}
 
-		case 99: // HALT
-			halt <- true
-			return
+func (d Direction) Vector() Vector2 {
+	return directionVectors[d]
+}
 
-		default:
-			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", ip, instruction, opcode))
+// Returns the direction of a vector of length one (or a diagonal one).
+func DirectionOf(v Vector2) (Direction, bool) {
+	for d, dv := range directionVectors {
+		if dv == v {
+			return Direction(d), true
 		}
 	}
+	return 0, false
 }
 
-// Integer power: compute a**b using binary powering algorithm
-// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
-// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
-func pow(a, b int64) int64 {
-	var p int64 = 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
-		}
-		b >>= 1
-		a *= a
+// Parses a direction given as a letter (U, D, L, R or N, S, W, E) or as an
+// arrow (^, v, <, >).
+func ParseDirection(char rune) (Direction, bool) {
+	switch char {
+	case 'U', 'N', '^':
+		return Up, true
+	case 'D', 'S', 'v':
+		return Down, true
+	case 'L', 'W', '<':
+		return Left, true
+	case 'R', 'E', '>':
+		return Right, true
 	}
-	return p
+	return 0, false
 }
 
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
+// The movement commands of intcode robots: 1 north, 2 south, 3 west, 4 east.
+var directionCommands = map[Direction]int64{Up: 1, Down: 2, Left: 3, Right: 4}
 
-type Vector2 struct {
-	x, y int
+func (d Direction) Command() int64 {
+	command, ok := directionCommands[d]
+	if !ok {
+		panic("no command for diagonal direction")
+	}
+	return command
 }
 
-func (v Vector2) Plus(other Vector2) Vector2 {
-	return Vector2{
-		x: v.x + other.x,
-		y: v.y + other.y,
+func DirectionOfCommand(command int64) (Direction, bool) {
+	for d, c := range directionCommands {
+		if c == command {
+			return d, true
+		}
 	}
+	return 0, false
 }
 
-func (v Vector2) Min(other Vector2) Vector2 {
-	return Vector2{
-		x: min(v.x, other.x),
-		y: min(v.y, other.y),
-	}
+func (v Vector2) Move(d Direction) Vector2 {
+	return v.Plus(d.Vector())
 }
 
-func (v Vector2) Max(other Vector2) Vector2 {
-	return Vector2{
-		x: max(v.x, other.x),
-		y: max(v.y, other.y),
-	}
+func (v Vector2) Neighbors4() []Vector2 {
+	return v.neighbors(Directions4)
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
+func (v Vector2) Neighbors8() []Vector2 {
+	return v.neighbors(Directions8)
 }
 
-func check(err error) {
-	if err != nil {
-		panic(err)
+func (v Vector2) neighbors(directions []Direction) []Vector2 {
+	result := make([]Vector2, len(directions))
+	for i, d := range directions {
+		result[i] = v.Move(d)
 	}
+	return result
 }
 
-func min(x, y int) int {
-	if y < x {
-		return y
+func check(err error) {
+	if err != nil {
+		panic(err)
 	}
-	return x
 }
 
 func max(x, y int) int {
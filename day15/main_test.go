@@ -0,0 +1,86 @@
+package day15
+
+import (
+	"reflect"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+// Parses a map from the puzzle statement. Spaces are unknown and O is the
+// oxygen system.
+func parseArea(lines []string) (grid map[Vector2]int, oxygenPos Vector2) {
+	grid = make(map[Vector2]int)
+	for y, line := range lines {
+		for x, char := range line {
+			pos := Vector2{x, y}
+			switch char {
+			case '#':
+				grid[pos] = Wall
+			case '.':
+				grid[pos] = Path
+			case 'O':
+				grid[pos] = Path
+				oxygenPos = pos
+			}
+		}
+	}
+	return
+}
+
+func TestFillTime(t *testing.T) {
+	grid, oxygenPos := parseArea([]string{
+		" ##   ",
+		"#..## ",
+		"#.#..#",
+		"#.O.# ",
+		" ###  ",
+	})
+
+	if got := fillTime(grid, oxygenPos); got != 4 {
+		t.Errorf("got %d minutes, want 4", got)
+	}
+}
+
+func TestNavigate(t *testing.T) {
+	grid, _ := parseArea([]string{
+		"#####",
+		"#...#",
+		"#.#.#",
+		"#####",
+	})
+
+	// A droid that accepts every command.
+	input := make(chan int64)
+	output := make(chan int64)
+	var commands []int64
+	done := make(chan bool)
+	go func() {
+		for command := range input {
+			commands = append(commands, command)
+			output <- 1
+		}
+		done <- true
+	}()
+
+	pos := navigate(Vector2{1, 2}, Vector2{3, 2}, grid, input, output)
+	close(input)
+	<-done
+
+	if pos != (Vector2{3, 2}) {
+		t.Errorf("ended at %v, want {3 2}", pos)
+	}
+
+	// North, east, east, south.
+	if want := []int64{1, 4, 4, 2}; !reflect.DeepEqual(commands, want) {
+		t.Errorf("got commands %v, want %v", commands, want)
+	}
+}
@@ -1,14 +1,13 @@
-package main
+package day16
 
 import (
-	"fmt"
 	"io/ioutil"
 	"strconv"
 	"strings"
 )
 
-func main() {
-	input := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	input := readFile(filename)
 
 	var values []byte
 	for _, char := range input {
@@ -24,40 +23,9 @@ func main() {
 
 	offset := toInt(input[:7])
 
-	{
-		fmt.Println("--- Part One ---")
-
-		for phase := 0; phase < 100; phase++ {
-			result := make([]byte, len(values))
-			for ri := 0; ri < len(result); ri++ {
-				acc := 0
-				length := ri + 1
-				for index := ri; index < len(values); {
-					for i := 0; i < length && index < len(values); i++ {
-						acc += int(values[index])
-						index++
-					}
-					index += length
-					for i := 0; i < length && index < len(values); i++ {
-						acc -= int(values[index])
-						index++
-					}
-					index += length
-				}
-				result[ri] = byte(abs(acc % 10))
-			}
-			values = result
-		}
-
-		for i := 0; i < 8; i++ {
-			fmt.Print(values[i])
-		}
-		fmt.Println()
-	}
+	partOne = message(phases(values, 100))
 
 	{
-		fmt.Println("--- Part Two ---")
-
 		// This assumes that offset >= len(longvalues)/2, in which case each
 		// output element is computed from the sum of the input elements with
 		// an equal or greater index. This can be efficiently computed by
@@ -72,11 +40,45 @@ func main() {
 			}
 		}
 
-		for i := 0; i < 8; i++ {
-			fmt.Print(longvalues[i])
+		partTwo = message(longvalues)
+	}
+
+	return partOne, partTwo
+}
+
+// Applies the given number of phases of the FFT to the values.
+func phases(values []byte, count int) []byte {
+	for phase := 0; phase < count; phase++ {
+		result := make([]byte, len(values))
+		for ri := 0; ri < len(result); ri++ {
+			acc := 0
+			length := ri + 1
+			for index := ri; index < len(values); {
+				for i := 0; i < length && index < len(values); i++ {
+					acc += int(values[index])
+					index++
+				}
+				index += length
+				for i := 0; i < length && index < len(values); i++ {
+					acc -= int(values[index])
+					index++
+				}
+				index += length
+			}
+			result[ri] = byte(abs(acc % 10))
 		}
-		fmt.Println()
+		values = result
+	}
+	return values
+}
+
+// Returns the first eight digits as a string.
+func message(values []byte) string {
+	var builder strings.Builder
+	for _, value := range values[:8] {
+		builder.WriteByte('0' + value)
 	}
+	return builder.String()
 }
 
 func readFile(filename string) string {
@@ -0,0 +1,55 @@
+package day16
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestPhases(t *testing.T) {
+	tests := []struct {
+		input  string
+		count  int
+		output string
+	}{
+		{"12345678", 1, "48226158"},
+		{"12345678", 4, "01029498"},
+		{"80871224585914546619083218645595", 100, "24176176"},
+		{"19617804207202209144916044189917", 100, "73745418"},
+		{"69317163492948606335995924319873", 100, "52432133"},
+	}
+
+	for _, test := range tests {
+		var values []byte
+		for _, char := range test.input {
+			values = append(values, byte(char-'0'))
+		}
+
+		if got := message(phases(values, test.count)); got != test.output {
+			t.Errorf("%s after %d phases: got %s, want %s", test.input, test.count, got, test.output)
+		}
+	}
+}
+
+func TestRealSignal(t *testing.T) {
+	tests := []struct {
+		input, partTwo string
+	}{
+		{"03036732577212944063491565474664", "84462026"},
+		{"02935109699940807407585447034323", "78725270"},
+		{"03081770884921959731165446850517", "53553731"},
+	}
+
+	for _, test := range tests {
+		_, partTwo := aoctest.Solve(t, Solve, test.input)
+		aoctest.Check(t, test.input, partTwo, test.partTwo)
+	}
+}
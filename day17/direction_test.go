@@ -0,0 +1,100 @@
+package day17
+
+import "testing"
+
+// The direction code is copied from template_vector.go, which is excluded
+// from the build and cannot be tested itself. Days 03, 11, 15 and 17 each
+// have a copy of the code and the same test for it.
+
+func TestDirectionTurns(t *testing.T) {
+	for _, d := range Directions8 {
+		if got := d.TurnLeft().TurnRight(); got != d {
+			t.Errorf("%d: left then right = %d", d, got)
+		}
+		if got := d.TurnRight().TurnRight(); got != d.Reverse() {
+			t.Errorf("%d: right twice = %d, reverse = %d", d, got, d.Reverse())
+		}
+		if got, v := d.Reverse().Vector(), d.Vector(); got != (Vector2{-v.x, -v.y}) {
+			t.Errorf("%d: reverse vector = %v", d, got)
+		}
+	}
+
+	if Up.TurnRight() != Right || Right.TurnRight() != Down || Down.TurnRight() != Left || Left.TurnRight() != Up {
+		t.Error("turning right does not go clockwise")
+	}
+	if UpLeft.TurnLeft() != DownLeft {
+		t.Errorf("up left turned left = %d", UpLeft.TurnLeft())
+	}
+}
+
+func TestDirectionVectors(t *testing.T) {
+	if Up.Vector() != (Vector2{0, -1}) || Right.Vector() != (Vector2{1, 0}) {
+		t.Error("up must point to negative y and right to positive x")
+	}
+
+	for _, d := range Directions8 {
+		got, ok := DirectionOf(d.Vector())
+		if !ok || got != d {
+			t.Errorf("%d: DirectionOf(%v) = %d, %v", d, d.Vector(), got, ok)
+		}
+	}
+
+	if _, ok := DirectionOf(Vector2{2, 0}); ok {
+		t.Error("DirectionOf accepted a vector of length two")
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	tests := map[rune]Direction{
+		'U': Up, 'N': Up, '^': Up,
+		'D': Down, 'S': Down, 'v': Down,
+		'L': Left, 'W': Left, '<': Left,
+		'R': Right, 'E': Right, '>': Right,
+	}
+	for char, want := range tests {
+		if got, ok := ParseDirection(char); !ok || got != want {
+			t.Errorf("ParseDirection(%q) = %d, %v, want %d", char, got, ok, want)
+		}
+	}
+
+	if _, ok := ParseDirection('x'); ok {
+		t.Error("ParseDirection accepted 'x'")
+	}
+}
+
+func TestDirectionCommands(t *testing.T) {
+	for command := int64(1); command <= 4; command++ {
+		d, ok := DirectionOfCommand(command)
+		if !ok || d.Command() != command {
+			t.Errorf("command %d: direction %d, %v", command, d, ok)
+		}
+	}
+
+	if Up.Command() != 1 || Down.Command() != 2 || Left.Command() != 3 || Right.Command() != 4 {
+		t.Error("commands do not match north, south, west, east")
+	}
+
+	if _, ok := DirectionOfCommand(5); ok {
+		t.Error("DirectionOfCommand accepted 5")
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	v := Vector2{3, 5}
+
+	n4 := v.Neighbors4()
+	if len(n4) != 4 || n4[0] != (Vector2{3, 4}) || n4[1] != (Vector2{4, 5}) {
+		t.Errorf("Neighbors4 = %v", n4)
+	}
+
+	seen := make(map[Vector2]bool)
+	for _, n := range v.Neighbors8() {
+		if n == v || n.x < v.x-1 || n.x > v.x+1 || n.y < v.y-1 || n.y > v.y+1 {
+			t.Errorf("%v is not a neighbor of %v", n, v)
+		}
+		seen[n] = true
+	}
+	if len(seen) != 8 {
+		t.Errorf("Neighbors8 returned %d distinct positions", len(seen))
+	}
+}
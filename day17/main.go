@@ -1,47 +1,53 @@
-package main
+package day17
 
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
+	"image"
+	"image/color"
+	"os"
 	"strconv"
 	"strings"
-)
 
-var (
-	Up    = Vector2{0, -1}
-	Down  = Vector2{0, 1}
-	Left  = Vector2{-1, 0}
-	Right = Vector2{1, 0}
+	"greenlightning.eu/aoc19/grid"
+	"greenlightning.eu/aoc19/intcode"
+	"greenlightning.eu/aoc19/render"
 )
 
+var Flags = flag.NewFlagSet("day17", flag.ContinueOnError)
+
 var (
-	turnLeft  = map[Vector2]Vector2{Up: Left, Left: Down, Down: Right, Right: Up}
-	turnRight = map[Vector2]Vector2{Up: Right, Right: Down, Down: Left, Left: Up}
+	printFlag  = Flags.Bool("print", false, "print camera image")
+	renderFlag = Flags.String("render", "", "render camera image to `file` (.png, .svg, .ans or text; - for the terminal)")
+	recordFlag = Flags.String("record", "", "record the session of the vacuum robot to `file`")
+	replayFlag = Flags.String("replay", "", "replay the session of the vacuum robot from `file`")
 )
 
-var printFlag = flag.Bool("print", false, "print camera image")
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
 
-func main() {
-	flag.Parse()
+	if *replayFlag != "" {
+		session, err := intcode.LoadSession(*replayFlag)
+		check(err)
 
-	text := readFile("input.txt")
+		// Wake up the robot.
+		program[0] = 2
 
-	var program []int64
-	for _, value := range strings.Split(text, ",") {
-		program = append(program, toInt64(value))
+		check(intcode.Replay(program, session))
+		fmt.Printf("Replayed %d events.\n", len(session.Events))
+		return
 	}
 
-	var grid []string
-	var width, height int
+	var camera grid.Dense
 
-	// Run the program and extract the camera image into grid.
+	// Run the program and extract the camera image.
 	{
 		input := make(chan int64)
 		output := make(chan int64)
 		halt := make(chan bool)
 
-		go emulate(program, input, output, halt)
+		go intcode.Run(intcode.MakeEmulator(program), input, output, halt)
 
 		var builder strings.Builder
 
@@ -56,58 +62,49 @@ func main() {
 			}
 		}
 
-		grid = strings.Split(strings.TrimSpace(builder.String()), "\n")
-		width, height = len(grid[0]), len(grid)
+		camera = grid.Parse(strings.Split(strings.TrimSpace(builder.String()), "\n"))
 	}
 
-	if *printFlag {
-		for _, line := range grid {
-			fmt.Println(line)
+	if *printFlag || *renderFlag != "" {
+		robot := color.RGBA{0xff, 0xc0, 0x40, 0xff}
+		style := render.Style{
+			Palette: map[int]render.Tile{
+				'#': {Glyph: '#', Color: color.RGBA{0x80, 0x80, 0x80, 0xff}},
+				'.': {Glyph: '.', Color: color.Black},
+				'^': {Glyph: '^', Color: robot},
+				'v': {Glyph: 'v', Color: robot},
+				'<': {Glyph: '<', Color: robot},
+				'>': {Glyph: '>', Color: robot},
+				'X': {Glyph: 'X', Color: color.RGBA{0xff, 0x40, 0x40, 0xff}},
+			},
 		}
-	}
 
-	{
-		fmt.Println("--- Part One ---")
-		sumOfAlignmentParameters := 0
-		for y := 1; y+1 < height; y++ {
-			for x := 1; x+1 < width; x++ {
-				if grid[y][x] == '#' && grid[y-1][x] == '#' && grid[y+1][x] == '#' && grid[y][x-1] == '#' && grid[y][x+1] == '#' {
-					sumOfAlignmentParameters += x * y
-				}
-			}
+		if *printFlag {
+			check(render.Text(os.Stdout, camera, style))
+		}
+		if *renderFlag != "" {
+			check(render.WriteFile(*renderFlag, camera, style))
 		}
-		fmt.Println(sumOfAlignmentParameters)
 	}
 
-	{
-		fmt.Println("--- Part Two ---")
+	partOne = fmt.Sprint(sumOfAlignmentParameters(camera))
 
+	{
 		// Wake up the robot.
 		program[0] = 2
 
 		// Find the robot.
-		var pos, dir Vector2
-		for y := 0; y < height; y++ {
-			for x := 0; x < width; x++ {
-				switch grid[y][x] {
-				case '^':
-					pos = Vector2{x, y}
-					dir = Up
-				case 'v':
-					pos = Vector2{x, y}
-					dir = Down
-				case '<':
-					pos = Vector2{x, y}
-					dir = Left
-				case '>':
-					pos = Vector2{x, y}
-					dir = Right
-				}
+		var pos Vector2
+		var dir Direction
+		for i, char := range camera.Cells {
+			if d, ok := ParseDirection(rune(char)); ok {
+				pos = Vector2{i % camera.Width, i / camera.Width}
+				dir = d
 			}
 		}
 
 		isScaffold := func(pos Vector2) bool {
-			return pos.x >= 0 && pos.y >= 0 && pos.x < width && pos.y < height && grid[pos.y][pos.x] == '#'
+			return camera.Get(image.Point{pos.x, pos.y}) == '#'
 		}
 
 		// Gather commands to follow the path.
@@ -116,18 +113,18 @@ func main() {
 		var path MoveList
 		for {
 			length := 0
-			for isScaffold(pos.Plus(dir)) {
-				pos = pos.Plus(dir)
+			for isScaffold(pos.Move(dir)) {
+				pos = pos.Move(dir)
 				length++
 			}
 			if length != 0 {
 				path = append(path, strconv.Itoa(length))
 			}
 
-			if newDir := turnLeft[dir]; isScaffold(pos.Plus(newDir)) {
+			if newDir := dir.TurnLeft(); isScaffold(pos.Move(newDir)) {
 				dir = newDir
 				path = append(path, "L")
-			} else if newDir := turnRight[dir]; isScaffold(pos.Plus(newDir)) {
+			} else if newDir := dir.TurnRight(); isScaffold(pos.Move(newDir)) {
 				dir = newDir
 				path = append(path, "R")
 			} else {
@@ -140,11 +137,21 @@ func main() {
 			panic("no solution found")
 		}
 
+		emulator := intcode.MakeEmulator(program)
+
+		if *recordFlag != "" {
+			session := new(intcode.Session)
+			emulator.Record(session)
+			defer func() {
+				check(intcode.SaveSession(*recordFlag, session))
+			}()
+		}
+
 		input := make(chan int64, 100)
 		output := make(chan int64)
 		halt := make(chan bool)
 
-		go emulate(program, input, output, halt)
+		go intcode.Run(emulator, input, output, halt)
 
 		functions := result[0]
 		main := strings.Join(functions[0], ",")
@@ -161,7 +168,7 @@ func main() {
 			select {
 			case char := <-output:
 				if char >= 128 {
-					fmt.Println(char)
+					partTwo = fmt.Sprint(char)
 				}
 
 			case <-halt:
@@ -169,6 +176,25 @@ func main() {
 			}
 		}
 	}
+
+	return partOne, partTwo
+}
+
+// Returns the sum of the alignment parameters of the scaffold intersections.
+func sumOfAlignmentParameters(camera grid.Dense) int {
+	sum := 0
+	for _, p := range grid.FindAll(camera, '#') {
+		scaffolds := 0
+		for _, neighbor := range grid.Neighbors4(camera, p) {
+			if camera.Get(neighbor) == '#' {
+				scaffolds++
+			}
+		}
+		if scaffolds == 4 {
+			sum += p.X * p.Y
+		}
+	}
+	return sum
 }
 
 type MoveList []string
@@ -191,7 +217,7 @@ func compressPath(path MoveList, fragments []MoveList, functions []MoveList) (re
 		for len(path) != 0 {
 			for i, function := range functions {
 				if hasPrefix(path, function) {
-					mainFunction = append(mainFunction, string('A'+i))
+					mainFunction = append(mainFunction, string(rune('A'+i)))
 					path = path[len(function):]
 				}
 			}
@@ -289,146 +315,119 @@ func index(list, sublist MoveList) int {
 	return -1
 }
 
-func emulate(program []int64, input <-chan int64, output chan<- int64, halt chan<- bool) {
-	// Copy the program into memory, so that we do not modify the original.
-	memory := make([]int64, len(program))
-	copy(memory, program)
+type Vector2 struct {
+	x, y int
+}
 
-	getMemoryPointer := func(index int64) *int64 {
-		// Grow memory, if index is out of range.
-		for int64(len(memory)) <= index {
-			memory = append(memory, 0)
-		}
-		return &memory[index]
+func (v Vector2) Plus(other Vector2) Vector2 {
+	return Vector2{
+		x: v.x + other.x,
+		y: v.y + other.y,
 	}
+}
 
-	var ip, relativeBase int64
-	for {
-		instruction := memory[ip]
-		opcode := instruction % 100
-
-		getParameter := func(offset int64) *int64 {
-			parameter := memory[ip+offset]
-			mode := instruction / pow(10, offset+1) % 10
-			switch mode {
-			case 0: // position mode
-				return getMemoryPointer(parameter)
-			case 1: // immediate mode
-				return &parameter
-			case 2: // relative mode
-				return getMemoryPointer(relativeBase + parameter)
-			default:
-				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", ip, instruction, offset, mode))
-			}
-		}
-
-		switch opcode {
-
-		case 1: // ADD
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a + *b
-			ip += 4
+// Direction is one of the eight compass directions, in clockwise order. The
+// y axis points down, like on screen and in the puzzle inputs.
+type Direction int
+
+const (
+	Up        Direction = 0
+	UpRight   Direction = 1
+	Right     Direction = 2
+	DownRight Direction = 3
+	Down      Direction = 4
+	DownLeft  Direction = 5
+	Left      Direction = 6
+	UpLeft    Direction = 7
+)
 
-		case 2: // MULTIPLY
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a * *b
-			ip += 4
+var (
+	Directions4 = []Direction{Up, Right, Down, Left}
+	Directions8 = []Direction{Up, UpRight, Right, DownRight, Down, DownLeft, Left, UpLeft}
+)
 
-		case 3: // INPUT
-			a := getParameter(1)
-			*a = <-input
-			ip += 2
+var directionVectors = [8]Vector2{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
 
-		case 4: // OUTPUT
-			a := getParameter(1)
-			output <- *a
-			ip += 2
+func (d Direction) TurnLeft() Direction {
+	return (d + 6) % 8
+}
 
-		case 5: // JUMP IF TRUE
-			a, b := getParameter(1), getParameter(2)
-			if *a != 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
+func (d Direction) TurnRight() Direction {
+	return (d + 2) % 8
+}
 
-		case 6: // JUMP IF FALSE
-			a, b := getParameter(1), getParameter(2)
-			if *a == 0 {
-				ip = *b
-			} else {
-				ip += 3
-			}
+func (d Direction) Reverse() Direction {
+	return (d + 4) % 8
+}
 
-		case 7: // LESS THAN
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a < *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
+func (d Direction) Vector() Vector2 {
+	return directionVectors[d]
+}
 
-		case 8: // EQUAL
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a == *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			ip += 4
+// Returns the direction of a vector of length one (or a diagonal one).
+func DirectionOf(v Vector2) (Direction, bool) {
+	for d, dv := range directionVectors {
+		if dv == v {
+			return Direction(d), true
+		}
+	}
+	return 0, false
+}
 
-		case 9: // RELATIVE BASE OFFSET
-			a := getParameter(1)
-			relativeBase += *a
-			ip += 2
+// Parses a direction given as a letter (U, D, L, R or N, S, W, E) or as an
+// arrow (^, v, <, >).
+func ParseDirection(char rune) (Direction, bool) {
+	switch char {
+	case 'U', 'N', '^':
+		return Up, true
+	case 'D', 'S', 'v':
+		return Down, true
+	case 'L', 'W', '<':
+		return Left, true
+	case 'R', 'E', '>':
+		return Right, true
+	}
+	return 0, false
+}
 
-		case 99: // HALT
-			halt <- true
-			return
+// The movement commands of intcode robots: 1 north, 2 south, 3 west, 4 east.
+var directionCommands = map[Direction]int64{Up: 1, Down: 2, Left: 3, Right: 4}
 
-		default:
-			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", ip, instruction, opcode))
-		}
+func (d Direction) Command() int64 {
+	command, ok := directionCommands[d]
+	if !ok {
+		panic("no command for diagonal direction")
 	}
+	return command
 }
 
-// Integer power: compute a**b using binary powering algorithm
-// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
-// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
-func pow(a, b int64) int64 {
-	var p int64 = 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
+func DirectionOfCommand(command int64) (Direction, bool) {
+	for d, c := range directionCommands {
+		if c == command {
+			return d, true
 		}
-		b >>= 1
-		a *= a
 	}
-	return p
+	return 0, false
 }
 
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
+func (v Vector2) Move(d Direction) Vector2 {
+	return v.Plus(d.Vector())
 }
 
-type Vector2 struct {
-	x, y int
+func (v Vector2) Neighbors4() []Vector2 {
+	return v.neighbors(Directions4)
 }
 
-func (v Vector2) Plus(other Vector2) Vector2 {
-	return Vector2{
-		x: v.x + other.x,
-		y: v.y + other.y,
-	}
+func (v Vector2) Neighbors8() []Vector2 {
+	return v.neighbors(Directions8)
 }
 
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
+func (v Vector2) neighbors(directions []Direction) []Vector2 {
+	result := make([]Vector2, len(directions))
+	for i, d := range directions {
+		result[i] = v.Move(d)
+	}
+	return result
 }
 
 func check(err error) {
@@ -0,0 +1,31 @@
+package day17
+
+import (
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+	"greenlightning.eu/aoc19/grid"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestAlignmentParameters(t *testing.T) {
+	camera := grid.Parse(strings.Split(`..#..........
+..#..........
+#######...###
+#.#...#...#.#
+#############
+..#...#...#..
+..#####...^..`, "\n"))
+
+	if got := sumOfAlignmentParameters(camera); got != 76 {
+		t.Errorf("got %d, want 76", got)
+	}
+}
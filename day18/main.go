@@ -1,25 +1,19 @@
-package main
+package day18
 
 import (
 	"bufio"
 	"fmt"
+	"image"
 	"math"
 	"os"
-)
 
-var (
-	Up    = Vector2{0, -1}
-	Down  = Vector2{0, 1}
-	Left  = Vector2{-1, 0}
-	Right = Vector2{1, 0}
+	"greenlightning.eu/aoc19/grid"
 )
 
-var directions = []Vector2{Up, Down, Left, Right}
-
 // An entity can be an entrance or a key.
 type Entity struct {
 	Char        byte
-	Position    Vector2
+	Position    image.Point
 	Connections []Connection
 }
 
@@ -33,74 +27,54 @@ type Connection struct {
 	Distance int
 }
 
-func main() {
-	lines := readLines("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	vault := grid.Parse(readLines(filename))
 
-	// Convert input into byte arrays, so that we can modify it later.
-	grid := make([][]byte, len(lines))
-	for y, line := range lines {
-		grid[y] = []byte(line)
-	}
-
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(run(grid))
-	}
+	partOne = fmt.Sprint(run(vault))
 
 	// Modify grid for part two.
 	{
 		// Find the original entrance.
-		var cx, cy int
-		for y, line := range grid {
-			for x, char := range line {
-				if char == '@' {
-					cx, cy = x, y
-				}
-			}
-		}
+		center, _ := grid.Find(vault, '@')
 
 		// Check surroundings and exit early if the input is not valid (e.g.
 		// the examples for part one).
-		for dy := -1; dy <= 1; dy++ {
-			for dx := -1; dx <= 1; dx++ {
-				if dy == 0 && dx == 0 {
-					continue
-				}
-				if grid[cy+dy][cx+dx] != '.' {
-					fmt.Println("Input not valid for part two.")
-					os.Exit(1)
-				}
-			}
+		neighbors := grid.Neighbors8(vault, center)
+		valid := len(neighbors) == 8
+		for _, p := range neighbors {
+			valid = valid && vault.Get(p) == '.'
+		}
+		if !valid {
+			fmt.Println("Input not valid for part two.")
+			return partOne, ""
 		}
 
 		// The new entrances need different characters, because I want to use
 		// the characters as unique map keys.
-		grid[cy-1][cx-1], grid[cy-1][cx], grid[cy-1][cx+1] = '@', '#', '$'
-		grid[cy+0][cx-1], grid[cy+0][cx], grid[cy+0][cx+1] = '#', '#', '#'
-		grid[cy+1][cx-1], grid[cy+1][cx], grid[cy+1][cx+1] = '%', '#', '&'
+		for y, row := range []string{"@#$", "###", "%#&"} {
+			for x := range row {
+				vault.Set(center.Add(image.Point{x - 1, y - 1}), row[x])
+			}
+		}
 	}
 
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(run(grid))
-	}
+	partTwo = fmt.Sprint(run(vault))
+	return partOne, partTwo
 }
 
-func run(grid [][]byte) int {
+func run(vault grid.Dense) int {
 	numKeys := 0
 	entities := make(map[byte]*Entity)
 
 	// Find entities.
-	for y, line := range grid {
-		for x, char := range line {
-			if isKey(char) {
-				numKeys++
-			}
-			if isEntrance(char) || isKey(char) {
-				entities[char] = &Entity{
-					Char:     char,
-					Position: Vector2{x, y},
-				}
+	for i, char := range vault.Cells {
+		if isKey(char) {
+			numKeys++
+		}
+		if isEntrance(char) || isKey(char) {
+			entities[char] = &Entity{
+				Char:     char,
+				Position: image.Point{i % vault.Width, i / vault.Width},
 			}
 		}
 	}
@@ -109,7 +83,7 @@ func run(grid [][]byte) int {
 	for _, entity := range entities {
 
 		type Item struct {
-			Position Vector2
+			Position image.Point
 			Required uint32
 			Distance int
 		}
@@ -117,20 +91,19 @@ func run(grid [][]byte) int {
 		var open []Item
 		open = append(open, Item{entity.Position, 0, 0})
 
-		visited := make(map[Vector2]bool)
+		visited := make(map[image.Point]bool)
 		visited[entity.Position] = true
 
 		for len(open) != 0 {
 			current := open[0]
 			open = open[1:]
 
-			for _, dir := range directions {
-				next := current.Position.Plus(dir)
+			for _, next := range grid.Neighbors4(vault, current.Position) {
 				if visited[next] {
 					continue
 				}
 
-				char := grid[next.y][next.x]
+				char := vault.Get(next)
 				if char == '.' || isEntrance(char) {
 					// We can walk over these tiles regularly.
 					visited[next] = true
@@ -231,17 +204,6 @@ func isUnlocked(unlocked uint32, bits uint32) bool {
 	return unlocked&bits == bits
 }
 
-type Vector2 struct {
-	x, y int
-}
-
-func (v Vector2) Plus(other Vector2) Vector2 {
-	return Vector2{
-		x: v.x + other.x,
-		y: v.y + other.y,
-	}
-}
-
 func readLines(filename string) []string {
 	file, err := os.Open(filename)
 	check(err)
@@ -0,0 +1,97 @@
+package day18
+
+import (
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+	"greenlightning.eu/aoc19/grid"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		vault string
+		steps int
+	}{
+		// The example with 136 steps is left out, because without doors
+		// the search tries almost every order of the keys.
+		{`#########
+#b.A.@.a#
+#########`, 8},
+		{`########################
+#f.D.E.e.C.b.A.@.a.B.c.#
+######################.#
+#d.....................#
+########################`, 86},
+		{`########################
+#...............b.C.D.f#
+#.######################
+#.....@.a.B.c.d.A.e.F.g#
+########################`, 132},
+		{`########################
+#@..............ac.GI.b#
+###d#e#f################
+###A#B#C################
+###g#h#i################
+########################`, 81},
+
+		// Part two, the entrances are renamed like in Solve.
+		{`#######
+#a.#Cd#
+##@#@##
+#######
+##@#@##
+#cB#Ab#
+#######`, 8},
+		{`###############
+#d.ABC.#.....a#
+######@#@######
+###############
+######@#@######
+#b.....#.....c#
+###############`, 24},
+		{`#############
+#DcBa.#.GhKl#
+#.###@#@#I###
+#e#d#####j#k#
+###C#@#@###J#
+#fEbA.#.FgHi#
+#############`, 32},
+		{`#############
+#g#f.D#..h#l#
+#F###e#E###.#
+#dCba@#@BcIJ#
+#############
+#nK.L@#@G...#
+#M###N#H###.#
+#o#m..#i#jk.#
+#############`, 72},
+	}
+
+	for _, test := range tests {
+		vault := grid.Parse(strings.Split(test.vault, "\n"))
+		renameEntrances(vault)
+
+		if got := run(vault); got != test.steps {
+			t.Errorf("got %d steps, want %d for\n%s", got, test.steps, test.vault)
+		}
+	}
+}
+
+// Gives the entrances after the first one the characters used by Solve.
+func renameEntrances(vault grid.Dense) {
+	entrances := grid.FindAll(vault, '@')
+	for i, char := range []byte("$%&") {
+		if i+1 < len(entrances) {
+			vault.Set(entrances[i+1], char)
+		}
+	}
+}
@@ -1,42 +1,56 @@
-package main
+package day19
 
 import (
 	"flag"
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
-)
+	"image"
+	"image/color"
+	"os"
 
-var printFlag = flag.Bool("print", false, "print beam")
+	"greenlightning.eu/aoc19/intcode"
+	"greenlightning.eu/aoc19/render"
+)
 
-var program []int64
+var Flags = flag.NewFlagSet("day19", flag.ContinueOnError)
 
-func main() {
-	flag.Parse()
+var (
+	printFlag  = Flags.Bool("print", false, "print beam")
+	renderFlag = Flags.String("render", "", "render beam to `file` (.png, .svg, .ans or text; - for the terminal)")
+)
 
-	text := readFile("input.txt")
+var program []int64
 
-	for _, value := range strings.Split(text, ",") {
-		program = append(program, toInt64(value))
-	}
+func Solve(filename string) (partOne, partTwo string) {
+	var err error
+	program, err = intcode.LoadProgram(filename)
+	check(err)
 
-	if *printFlag {
-		for y := 0; y < 80; y++ {
-			for x := 0; x < 80; x++ {
+	if *printFlag || *renderFlag != "" {
+		beam := render.MakeDense(80, 80)
+		for y := 0; y < beam.Height; y++ {
+			for x := 0; x < beam.Width; x++ {
 				if probe(x, y) {
-					fmt.Print("#")
-				} else {
-					fmt.Print(".")
+					beam.Set(image.Point{x, y}, 1)
 				}
 			}
-			fmt.Println()
+		}
+
+		style := render.Style{
+			Palette: map[int]render.Tile{
+				0: {Glyph: '.', Color: color.Black},
+				1: {Glyph: '#', Color: color.RGBA{0x40, 0xc0, 0xff, 0xff}},
+			},
+		}
+
+		if *printFlag {
+			check(render.Text(os.Stdout, beam, style))
+		}
+		if *renderFlag != "" {
+			check(render.WriteFile(*renderFlag, beam, style))
 		}
 	}
 
 	{
-		fmt.Println("--- Part One ---")
-
 		count := 0
 
 		for y := 0; y < 50; y++ {
@@ -47,32 +61,27 @@ func main() {
 			}
 		}
 
-		fmt.Println(count)
+		partOne = fmt.Sprint(count)
 	}
 
-	{
-		fmt.Println("--- Part Two ---")
+	startX, startY := 0, 0
+	for {
+		if !probe(startX, startY) {
+			startX++
+		}
+
+		x, y := startX, startY
 
-		startX, startY := 0, 0
 		for {
-			if !probe(startX, startY) {
-				startX++
+			if probe(x, y) && probe(x+99, y) && probe(x, y+99) {
+				return partOne, fmt.Sprint(x*10000 + y)
 			}
 
-			x, y := startX, startY
+			x++
 
-			for {
-				if probe(x, y) && probe(x+99, y) && probe(x, y+99) {
-					fmt.Println(x*10000 + y)
-					return
-				}
-
-				x++
-
-				if !probe(x+99, y) {
-					startY++
-					break
-				}
+			if !probe(x+99, y) {
+				startY++
+				break
 			}
 		}
 	}
@@ -210,18 +219,6 @@ func pow(a, b int64) int64 {
 	return p
 }
 
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
-
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
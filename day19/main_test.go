@@ -0,0 +1,26 @@
+package day19
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+// A drone program for a beam that covers x from y to 2y in each row y.
+const coneProgram = "3,50,3,51,1002,51,2,52,7,52,50,53,7,50,51,54,1,53,54,55,1008,55,0,56,4,56,99"
+
+func TestCone(t *testing.T) {
+	// Part one counts 1 + (2 + ... + 25) + (25 + ... + 1) points. The first
+	// square fits where x = y+99 and x+99 = 2y.
+	partOne, partTwo := aoctest.Solve(t, Solve, coneProgram)
+	aoctest.Check(t, "part one", partOne, "650")
+	aoctest.Check(t, "part two", partTwo, "2970198")
+}
@@ -1,22 +1,16 @@
-package main
+package day20
 
 import (
 	"bufio"
 	"fmt"
+	"image"
 	"os"
-)
 
-var (
-	Up    = Vector2{0, -1}
-	Down  = Vector2{0, 1}
-	Left  = Vector2{-1, 0}
-	Right = Vector2{1, 0}
+	"greenlightning.eu/aoc19/grid"
 )
 
-var directions = []Vector2{Up, Down, Left, Right}
-
 type Cell struct {
-	Pos         Vector2
+	Pos         image.Point
 	Connections []Connection
 }
 
@@ -30,63 +24,53 @@ type Label struct {
 	Cells []*Cell
 }
 
-func main() {
-	lines := readLines("input.txt")
-	width, height := len(lines[0]), len(lines)
+func Solve(filename string) (partOne, partTwo string) {
+	maze := grid.Parse(readLines(filename))
 
-	cells := make(map[Vector2]*Cell)
-	labels := make(map[Vector2]*Label)
+	cells := make(map[image.Point]*Cell)
+	labels := make(map[image.Point]*Label)
 	labelsByName := make(map[string]*Label)
 
 	// Create cells and labels.
-	for y, line := range lines {
-		for x, char := range line {
-			if char != '.' {
-				continue
-			}
-
-			pos := Vector2{x, y}
-			cell := &Cell{Pos: pos}
-			cells[pos] = cell
+	for _, pos := range grid.FindAll(maze, '.') {
+		cell := &Cell{Pos: pos}
+		cells[pos] = cell
 
-			for _, dir := range directions {
-				next := pos.Plus(dir)
-				nextchar := lines[next.y][next.x]
+		for _, next := range grid.Neighbors4(maze, pos) {
+			nextchar := maze.Get(next)
 
-				if !(nextchar >= 'A' && nextchar <= 'Z') {
-					continue
-				}
+			if !(nextchar >= 'A' && nextchar <= 'Z') {
+				continue
+			}
 
-				beyondnext := next.Plus(dir)
-				beyondnextchar := lines[beyondnext.y][beyondnext.x]
+			dir := next.Sub(pos)
+			beyondnextchar := maze.Get(next.Add(dir))
 
-				name := string(beyondnextchar) + string(nextchar)
-				if dir.x >= 0 && dir.y >= 0 {
-					name = string(nextchar) + string(beyondnextchar)
-				}
-
-				label := labelsByName[name]
-				if label == nil {
-					label = &Label{Name: name}
-					labelsByName[name] = label
-				}
+			name := string(beyondnextchar) + string(nextchar)
+			if dir.X >= 0 && dir.Y >= 0 {
+				name = string(nextchar) + string(beyondnextchar)
+			}
 
-				labels[next] = label
-				label.Cells = append(label.Cells, cell)
+			label := labelsByName[name]
+			if label == nil {
+				label = &Label{Name: name}
+				labelsByName[name] = label
 			}
+
+			labels[next] = label
+			label.Cells = append(label.Cells, cell)
 		}
 	}
 
 	// Connect cells.
 	for _, cell := range cells {
-		for _, dir := range directions {
-			next := cell.Pos.Plus(dir)
+		for _, next := range grid.Neighbors4(maze, cell.Pos) {
 			if nextCell := cells[next]; nextCell != nil {
 				cell.Connections = append(cell.Connections, Connection{nextCell, 0})
 			}
 			if nextLabel := labels[next]; nextLabel != nil {
 				offset := 1
-				if next.x <= 2 || next.x >= width-2 || next.y <= 2 || next.y >= height-2 {
+				if next.X <= 2 || next.X >= maze.Width-2 || next.Y <= 2 || next.Y >= maze.Height-2 {
 					offset = -1
 				}
 				for _, nextCell := range nextLabel.Cells {
@@ -102,15 +86,7 @@ func main() {
 	start := labelsByName["AA"].Cells[0]
 	target := labelsByName["ZZ"].Cells[0]
 
-	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(findDistance(start, target, false))
-	}
-
-	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println(findDistance(start, target, true))
-	}
+	return fmt.Sprint(findDistance(start, target, false)), fmt.Sprint(findDistance(start, target, true))
 }
 
 func findDistance(start, target *Cell, recursive bool) int {
@@ -132,7 +108,7 @@ func findDistance(start, target *Cell, recursive bool) int {
 	visited := make(map[Position]bool)
 	visited[startItem.Position] = true
 
-	for {
+	for len(open) != 0 {
 		item := open[0]
 		open = open[1:]
 
@@ -155,17 +131,6 @@ func findDistance(start, target *Cell, recursive bool) int {
 	return -1
 }
 
-type Vector2 struct {
-	x, y int
-}
-
-func (v Vector2) Plus(other Vector2) Vector2 {
-	return Vector2{
-		x: v.x + other.x,
-		y: v.y + other.y,
-	}
-}
-
 func readLines(filename string) []string {
 	file, err := os.Open(filename)
 	check(err)
@@ -1,10 +1,10 @@
-package main
+package day21
 
 import (
 	"fmt"
-	"io/ioutil"
-	"strconv"
 	"strings"
+
+	"greenlightning.eu/aoc19/intcode"
 )
 
 var scriptOne = `NOT A J
@@ -29,33 +29,20 @@ AND T J
 RUN
 `
 
-func main() {
-	text := readFile("input.txt")
-
-	var program []int64
-	for _, value := range strings.Split(text, ",") {
-		program = append(program, toInt64(value))
-	}
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
 
-	{
-		fmt.Println("--- Part One ---")
-		result, output := execute(program, scriptOne)
+	// If the droid falls into space, show its last moments instead.
+	answer := func(script string) string {
+		result, output := execute(program, script)
 		if result != 0 {
-			fmt.Println(result)
-		} else {
-			fmt.Println(output)
+			return fmt.Sprint(result)
 		}
+		return output
 	}
 
-	{
-		fmt.Println("--- Part Two ---")
-		result, output := execute(program, scriptTwo)
-		if result != 0 {
-			fmt.Println(result)
-		} else {
-			fmt.Println(output)
-		}
-	}
+	return answer(scriptOne), answer(scriptTwo)
 }
 
 func execute(program []int64, script string) (int64, string) {
@@ -206,18 +193,6 @@ func pow(a, b int64) int64 {
 	return p
 }
 
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
-
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,105 @@
+package day21
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+// Runs a springscript on the hull, where the droid starts on the first tile,
+// and reports whether it gets past the end without falling into a hole.
+func survives(t *testing.T, script, hull string) bool {
+	t.Helper()
+
+	lines := strings.Split(strings.TrimSpace(script), "\n")
+	if len(lines)-1 > 15 {
+		t.Fatalf("%d instructions, the droid only accepts 15", len(lines)-1)
+	}
+
+	sensors := 4
+	switch lines[len(lines)-1] {
+	case "WALK":
+	case "RUN":
+		sensors = 9
+	default:
+		t.Fatalf("script ends with %q", lines[len(lines)-1])
+	}
+
+	ground := func(pos int) bool {
+		return pos >= len(hull) || hull[pos] == '#'
+	}
+
+	for pos := 0; pos < len(hull); {
+		registers := map[byte]bool{'T': false, 'J': false}
+		for i := 0; i < sensors; i++ {
+			registers['A'+byte(i)] = ground(pos + 1 + i)
+		}
+
+		for _, line := range lines[:len(lines)-1] {
+			var op string
+			var x, y byte
+			if _, err := fmt.Sscanf(line, "%s %c %c", &op, &x, &y); err != nil {
+				t.Fatalf("%q: %v", line, err)
+			}
+			if _, ok := registers[x]; !ok || (y != 'T' && y != 'J') {
+				t.Fatalf("%q: invalid registers", line)
+			}
+			switch op {
+			case "AND":
+				registers[y] = registers[x] && registers[y]
+			case "OR":
+				registers[y] = registers[x] || registers[y]
+			case "NOT":
+				registers[y] = !registers[x]
+			default:
+				t.Fatalf("%q: unknown instruction", line)
+			}
+		}
+
+		if registers['J'] {
+			pos += 4
+		} else {
+			pos++
+		}
+		if !ground(pos) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestScripts(t *testing.T) {
+	tests := []struct {
+		hull             string
+		partOne, partTwo bool
+	}{
+		{"#####.###########", true, true},
+		{"#####..#.########", true, true},
+		{"#####...#########", true, true},
+		{"#####.#..########", true, true},
+		{"#####.##.#.######", true, true},
+		// Walking jumps too early and lands between two holes, which only
+		// the longer range of RUN can see.
+		{"#####.#.##..#.###", false, true},
+	}
+
+	for _, test := range tests {
+		if got := survives(t, scriptOne, test.hull); got != test.partOne {
+			t.Errorf("%s: walking survives = %v, want %v", test.hull, got, test.partOne)
+		}
+		if got := survives(t, scriptTwo, test.hull); got != test.partTwo {
+			t.Errorf("%s: running survives = %v, want %v", test.hull, got, test.partTwo)
+		}
+	}
+}
@@ -1,12 +1,11 @@
-package main
+package day22
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
-	"math/big"
-	"os"
-	"regexp"
-	"strconv"
+
+	"greenlightning.eu/aoc19/numtheory"
+	"greenlightning.eu/aoc19/parse"
 )
 
 const (
@@ -20,79 +19,30 @@ type Shuffle struct {
 	Value int64
 }
 
-func main() {
-	lines := readLines("input.txt")
-
-	dealStackRegex := regexp.MustCompile(`^deal into new stack$`)
-	dealIncrementRegex := regexp.MustCompile(`^deal with increment (\d+)$`)
-	cutRegex := regexp.MustCompile(`^cut (-?\d+)$`)
-
-	var input []Shuffle
-	for _, line := range lines {
-		if match := dealStackRegex.FindStringSubmatch(line); match != nil {
-			input = append(input, Shuffle{KindDealStack, 0})
-
-		} else if match := dealIncrementRegex.FindStringSubmatch(line); match != nil {
-			increment := toInt64(match[1])
-			input = append(input, Shuffle{KindDealIncrement, increment})
-
-		} else if match := cutRegex.FindStringSubmatch(line); match != nil {
-			cut := toInt64(match[1])
-			input = append(input, Shuffle{KindCut, cut})
+var (
+	dealStackPattern     = parse.MustCompile(`deal into new stack`)
+	dealIncrementPattern = parse.MustCompile(`deal with increment (\d+)`)
+	cutPattern           = parse.MustCompile(`cut (-?\d+)`)
+)
 
-		} else {
-			panic(line)
-		}
-	}
+func Solve(filename string) (partOne, partTwo string) {
+	input, err := parseShuffles(filename)
+	check(err)
 
 	{
-		fmt.Println("--- Part One ---")
-
 		const count = 10007
 
-		cards := make([]int, count)
-		for index := range cards {
-			cards[index] = index
-		}
-
-		tmp := make([]int, count)
-
-		shuffles := compact(input, count)
-
-		for _, shuffle := range shuffles {
-			switch shuffle.Kind {
-			case KindDealStack:
-				for index, card := range cards {
-					tmp[len(cards)-1-index] = card
-				}
-
-			case KindDealIncrement:
-				var index int64
-				increment := shuffle.Value
-				for _, card := range cards {
-					tmp[index] = card
-					index = (index + increment) % count
-				}
-
-			case KindCut:
-				cut := shuffle.Value
-				copy(tmp, cards[cut:])
-				copy(tmp[count-cut:], cards)
-			}
-			cards, tmp = tmp, cards
-		}
+		cards := deal(compact(input, count), count)
 
 		for index, card := range cards {
 			if card == 2019 {
-				fmt.Println(index)
+				partOne = fmt.Sprint(index)
 				break
 			}
 		}
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
-
 		const count = 119315717514047
 		const iterations = 101741582076661
 
@@ -109,30 +59,93 @@ func main() {
 			factor = compact(factor, count)
 		}
 
-		pos := big.NewInt(2020)
+		var pos int64 = 2020
 		for _, shuffle := range shuffles {
 			if shuffle.Kind == KindDealIncrement {
 				increment := shuffle.Value
-				pos.Mul(pos, big.NewInt(increment))
-				pos.Mod(pos, big.NewInt(count))
+				pos = numtheory.MulMod(pos, increment, count)
 
 			} else if shuffle.Kind == KindDealStack {
-				pos.Sub(big.NewInt(count-1), pos)
+				pos = count - 1 - pos
 
 			} else if shuffle.Kind == KindCut {
 				cut := shuffle.Value
+				pos = numtheory.Mod(pos-cut, count)
+			}
+		}
 
-				if pos.Int64() < cut {
-					pos.Add(pos, big.NewInt(count-cut))
-				} else {
-					pos.Sub(pos, big.NewInt(cut))
-				}
+		partTwo = fmt.Sprint(pos)
+	}
+
+	return partOne, partTwo
+}
+
+func parseShuffles(filename string) ([]Shuffle, error) {
+	var input []Shuffle
+	err := parse.Lines(filename, func(line string) error {
+		var value int64
+		switch {
+		case dealStackPattern.MatchString(line):
+			input = append(input, Shuffle{KindDealStack, 0})
+
+		case dealIncrementPattern.MatchString(line):
+			if err := dealIncrementPattern.Scan(line, &value); err != nil {
+				return err
+			}
+			if value == 0 {
+				return errors.New("increment must not be zero")
+			}
+			input = append(input, Shuffle{KindDealIncrement, value})
+
+		case cutPattern.MatchString(line):
+			if err := cutPattern.Scan(line, &value); err != nil {
+				return err
 			}
+			input = append(input, Shuffle{KindCut, value})
+
+		default:
+			return errors.New("unknown shuffle")
 		}
+		return nil
+	})
+	return input, err
+}
 
-		fmt.Println(pos.Int64())
+// Shuffles a factory order deck of count cards. The shuffles do not have to
+// be compacted: negative cuts, which take cards from the bottom of the deck,
+// are converted to the equivalent cut from the top.
+func deal(shuffles []Shuffle, count int64) []int {
+	cards := make([]int, count)
+	for index := range cards {
+		cards[index] = index
 	}
 
+	tmp := make([]int, count)
+
+	for _, shuffle := range shuffles {
+		switch shuffle.Kind {
+		case KindDealStack:
+			for index, card := range cards {
+				tmp[len(cards)-1-index] = card
+			}
+
+		case KindDealIncrement:
+			var index int64
+			increment := shuffle.Value
+			for _, card := range cards {
+				tmp[index] = card
+				index = (index + increment) % count
+			}
+
+		case KindCut:
+			cut := (shuffle.Value%count + count) % count // normalize negative values
+			copy(tmp, cards[cut:])
+			copy(tmp[count-cut:], cards)
+		}
+		cards, tmp = tmp, cards
+	}
+
+	return cards
 }
 
 func compact(input []Shuffle, count int64) []Shuffle {
@@ -211,29 +224,26 @@ func compact(input []Shuffle, count int64) []Shuffle {
 	//
 	{
 		compacted := make([]Shuffle, 0, len(input))
-		cut := big.NewInt(0)
+		var cut int64
 		for _, shuffle := range input {
 			switch shuffle.Kind {
 			case KindDealStack:
-				if value := cut.Int64(); value != 0 {
-					compacted = append(compacted, Shuffle{KindCut, value})
-					cut.SetInt64(0)
+				if cut != 0 {
+					compacted = append(compacted, Shuffle{KindCut, cut})
+					cut = 0
 				}
 				compacted = append(compacted, shuffle)
 
 			case KindDealIncrement:
 				compacted = append(compacted, shuffle)
-				cut.Mul(cut, big.NewInt(shuffle.Value))
-				cut.Mod(cut, big.NewInt(count))
+				cut = numtheory.MulMod(cut, shuffle.Value, count)
 
 			case KindCut:
-				cut.Add(cut, big.NewInt(shuffle.Value))
-				cut.Mod(cut, big.NewInt(count))
+				cut = numtheory.AddMod(cut, shuffle.Value, count)
 			}
 		}
-		if value := cut.Int64(); value != 0 {
-			compacted = append(compacted, Shuffle{KindCut, value})
-			cut.SetInt64(0)
+		if cut != 0 {
+			compacted = append(compacted, Shuffle{KindCut, cut})
 		}
 		input = compacted
 	}
@@ -249,24 +259,22 @@ func compact(input []Shuffle, count int64) []Shuffle {
 	//
 	{
 		compacted := make([]Shuffle, 0, len(input))
-		increment := big.NewInt(1)
+		var increment int64 = 1
 		for _, shuffle := range input {
 			switch shuffle.Kind {
 			case KindDealIncrement:
-				increment.Mul(increment, big.NewInt(shuffle.Value))
-				increment.Mod(increment, big.NewInt(count))
+				increment = numtheory.MulMod(increment, shuffle.Value, count)
 
 			default:
-				if value := increment.Int64(); value != 1 {
-					compacted = append(compacted, Shuffle{KindDealIncrement, value})
-					increment.SetInt64(1)
+				if increment != 1 {
+					compacted = append(compacted, Shuffle{KindDealIncrement, increment})
+					increment = 1
 				}
 				compacted = append(compacted, shuffle)
 			}
 		}
-		if value := increment.Int64(); value != 1 {
-			compacted = append(compacted, Shuffle{KindDealIncrement, value})
-			increment.SetInt64(1)
+		if increment != 1 {
+			compacted = append(compacted, Shuffle{KindDealIncrement, increment})
 		}
 		input = compacted
 	}
@@ -274,26 +282,6 @@ func compact(input []Shuffle, count int64) []Shuffle {
 	return input
 }
 
-func readLines(filename string) []string {
-	file, err := os.Open(filename)
-	check(err)
-	defer file.Close()
-
-	scanner := bufio.NewScanner(file)
-
-	var lines []string
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
-	}
-	return lines
-}
-
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
-
 func check(err error) {
 	if err != nil {
 		panic(err)
@@ -0,0 +1,66 @@
+package day22
+
+import (
+	"os"
+	"reflect"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestExamples(t *testing.T) {
+	tests := []struct {
+		shuffles string
+		cards    []int
+	}{
+		{"deal with increment 7\ndeal into new stack\ndeal into new stack\n", []int{0, 3, 6, 9, 2, 5, 8, 1, 4, 7}},
+		{"cut 6\ndeal with increment 7\ndeal into new stack\n", []int{3, 0, 7, 4, 1, 8, 5, 2, 9, 6}},
+		{"deal with increment 7\ndeal with increment 9\ncut -2\n", []int{6, 3, 0, 7, 4, 1, 8, 5, 2, 9}},
+		{"deal into new stack\ncut -2\ndeal with increment 7\ncut 8\ncut -4\ndeal with increment 7\ncut 3\ndeal with increment 9\ndeal with increment 3\ncut -1\n", []int{9, 2, 5, 8, 1, 4, 7, 0, 3, 6}},
+	}
+
+	for _, test := range tests {
+		shuffles := parseExample(t, test.shuffles)
+
+		if got := deal(shuffles, 10); !reflect.DeepEqual(got, test.cards) {
+			t.Errorf("got %v, want %v for %q", got, test.cards, test.shuffles)
+		}
+
+		// Compacting must not change the result.
+		if got := deal(compact(shuffles, 10), 10); !reflect.DeepEqual(got, test.cards) {
+			t.Errorf("compacted: got %v, want %v for %q", got, test.cards, test.shuffles)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{"deal with increment 0\n", "cut\n", "shuffle\n"} {
+		filename := aoctest.TempFile(t, input)
+		_, err := parseShuffles(filename)
+		os.Remove(filename)
+		if err == nil {
+			t.Errorf("%q: expected an error", input)
+		}
+	}
+}
+
+func parseExample(t *testing.T, input string) []Shuffle {
+	t.Helper()
+
+	filename := aoctest.TempFile(t, input)
+	defer os.Remove(filename)
+
+	shuffles, err := parseShuffles(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return shuffles
+}
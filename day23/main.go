@@ -1,60 +1,96 @@
-package main
+package day23
 
 import (
+	"flag"
 	"fmt"
-	"io/ioutil"
-	"strconv"
-	"strings"
+	"io"
+	"os"
+
+	"greenlightning.eu/aoc19/intcode"
+)
+
+var Flags = flag.NewFlagSet("day23", flag.ContinueOnError)
+
+var (
+	udpFlag     = Flags.Bool("udp", false, "send packets as UDP datagrams over the loopback interface")
+	lossFlag    = Flags.Float64("loss", 0, "`probability` of dropping a packet (with -udp)")
+	reorderFlag = Flags.Float64("reorder", 0, "`probability` of delaying a packet, so that it arrives out of order (with -udp)")
+	seedFlag    = Flags.Int64("seed", 1, "random `seed` for packet loss and reordering")
+	traceFlag   = Flags.String("trace", "", "write a log of all packets to `file` (not with -udp)")
+	statsFlag   = Flags.Bool("stats", false, "print packet statistics and the NAT wake-ups (not with -udp)")
 )
 
-func main() {
-	text := readFile("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
+
+	if *udpFlag {
+		if *traceFlag != "" || *statsFlag {
+			panic("-trace and -stats are not supported with -udp")
+		}
 
-	var program []int64
-	for _, value := range strings.Split(text, ",") {
-		program = append(program, toInt64(value))
+		partOne, partTwo, err := runUDP(program, 50, *lossFlag, *reorderFlag, *seedFlag)
+		check(err)
+		return partOne, partTwo
 	}
 
-	computers := make([]*Emulator, 50)
+	computers := make([]*intcode.Emulator, 50)
 
 	for i := range computers {
-		computers[i] = makeEmulator(program, int64(i))
+		computers[i] = intcode.MakeEmulator(program, int64(i))
 	}
 
+	var log io.Writer
+	if *traceFlag != "" {
+		file, err := os.Create(*traceFlag)
+		check(err)
+		defer file.Close()
+		log = file
+	}
+
+	trace := makeTrace(len(computers), log)
+
 	var natInitialized bool
 	var natX, natY int64
 
 	delivered := make(map[int64]bool)
 
+	round := 0
+
+loop:
 	for {
-		for _, computer := range computers {
+		round++
+
+		for source, computer := range computers {
 			for waiting := 0; waiting < 2; {
-				address, status := emulate(computer)
-				if status == EmulatorStatusOutput {
-					x, status := emulate(computer)
-					if status != EmulatorStatusOutput {
+				address, status := computer.Emulate()
+				if status == intcode.EmulatorStatusOutput {
+					x, status := computer.Emulate()
+					if status != intcode.EmulatorStatusOutput {
 						panic("expected output")
 					}
 
-					y, status := emulate(computer)
-					if status != EmulatorStatusOutput {
+					y, status := computer.Emulate()
+					if status != intcode.EmulatorStatusOutput {
 						panic("expected output")
 					}
 
-					if address == 255 {
+					trace.packet(round, int64(source), address, Packet{x, y})
+
+					if address == natAddress {
 						if !natInitialized {
 							natInitialized = true
-							fmt.Println("--- Part One ---")
-							fmt.Println(y)
+							partOne = fmt.Sprint(y)
 						}
 						natX, natY = x, y
 					} else {
 						target := computers[address]
-						target.input = append(target.input, x, y)
+						target.Write(x, y)
 					}
 					waiting = 0
-				} else if status == EmulatorStatusWaitingForInput {
-					computer.input = append(computer.input, -1)
+				} else if status == intcode.EmulatorStatusWaitingForInput {
+					computer.Write(-1)
+					trace.idlePoll(int64(source))
 					waiting++
 				} else {
 					panic("halted")
@@ -64,179 +100,30 @@ func main() {
 
 		waiting := 0
 		for _, computer := range computers {
-			if len(computer.input) == 1 {
+			if computer.Pending() == 1 {
 				waiting++
 			}
 		}
 
 		if waiting == len(computers) {
 			if delivered[natY] {
-				fmt.Println("--- Part Two ---")
-				fmt.Println(natY)
-				return
+				trace.repeat(round, Packet{natX, natY})
+				partTwo = fmt.Sprint(natY)
+				break loop
 			}
 			delivered[natY] = true
-			computers[0].input = append(computers[0].input, natX, natY)
+			trace.wakeup(round, Packet{natX, natY})
+			computers[0].Write(natX, natY)
 		}
 	}
-}
-
-// This version of the intcode emulator does not use goroutines.
-
-type EmulatorStatus int
-
-const (
-	EmulatorStatusHalted          EmulatorStatus = 0
-	EmulatorStatusOutput          EmulatorStatus = 1
-	EmulatorStatusWaitingForInput EmulatorStatus = 2
-)
-
-type Emulator struct {
-	memory           []int64
-	input            []int64
-	ip, relativeBase int64
-}
 
-func makeEmulator(program []int64, input ...int64) *Emulator {
-	// Copy the program into memory, so that we do not modify the original.
-	memory := make([]int64, len(program))
-	copy(memory, program)
+	check(trace.flush())
 
-	return &Emulator{
-		memory: memory,
-		input:  input,
+	if *statsFlag {
+		trace.print(os.Stdout, round)
 	}
-}
-
-func emulate(emulator *Emulator, input ...int64) (int64, EmulatorStatus) {
-	emulator.input = append(emulator.input, input...)
 
-	getMemoryPointer := func(index int64) *int64 {
-		// Grow memory, if index is out of range.
-		for int64(len(emulator.memory)) <= index {
-			emulator.memory = append(emulator.memory, 0)
-		}
-		return &emulator.memory[index]
-	}
-
-	for {
-		instruction := emulator.memory[emulator.ip]
-		opcode := instruction % 100
-
-		getParameter := func(offset int64) *int64 {
-			parameter := emulator.memory[emulator.ip+offset]
-			mode := instruction / pow(10, offset+1) % 10
-			switch mode {
-			case 0: // position mode
-				return getMemoryPointer(parameter)
-			case 1: // immediate mode
-				return &parameter
-			case 2: // relative mode
-				return getMemoryPointer(emulator.relativeBase + parameter)
-			default:
-				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", emulator.ip, instruction, offset, mode))
-			}
-		}
-
-		switch opcode {
-
-		case 1: // ADD
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a + *b
-			emulator.ip += 4
-
-		case 2: // MULTIPLY
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a * *b
-			emulator.ip += 4
-
-		case 3: // INPUT
-			if len(emulator.input) == 0 {
-				return 0, EmulatorStatusWaitingForInput
-			}
-			a := getParameter(1)
-			*a = emulator.input[0]
-			emulator.input = emulator.input[1:]
-			emulator.ip += 2
-
-		case 4: // OUTPUT
-			a := getParameter(1)
-			emulator.ip += 2
-			return *a, EmulatorStatusOutput
-
-		case 5: // JUMP IF TRUE
-			a, b := getParameter(1), getParameter(2)
-			if *a != 0 {
-				emulator.ip = *b
-			} else {
-				emulator.ip += 3
-			}
-
-		case 6: // JUMP IF FALSE
-			a, b := getParameter(1), getParameter(2)
-			if *a == 0 {
-				emulator.ip = *b
-			} else {
-				emulator.ip += 3
-			}
-
-		case 7: // LESS THAN
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a < *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			emulator.ip += 4
-
-		case 8: // EQUAL
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a == *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			emulator.ip += 4
-
-		case 9: // RELATIVE BASE OFFSET
-			a := getParameter(1)
-			emulator.relativeBase += *a
-			emulator.ip += 2
-
-		case 99: // HALT
-			return 0, EmulatorStatusHalted
-
-		default:
-			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", emulator.ip, instruction, opcode))
-		}
-	}
-}
-
-// Integer power: compute a**b using binary powering algorithm
-// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
-// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
-func pow(a, b int64) int64 {
-	var p int64 = 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
-		}
-		b >>= 1
-		a *= a
-	}
-	return p
-}
-
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
-
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
+	return partOne, partTwo
 }
 
 func check(err error) {
@@ -0,0 +1,72 @@
+package day23
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+	"greenlightning.eu/aoc19/intcode"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestEncode(t *testing.T) {
+	for _, packet := range []Packet{{0, 0}, {1, -1}, {math.MaxInt64, math.MinInt64}} {
+		data := encode(packet)
+		if len(data) != 16 {
+			t.Errorf("%v: encoded to %d bytes, want 16", packet, len(data))
+		}
+		if got := decode(data); got != packet {
+			t.Errorf("%v: decoded to %v", packet, got)
+		}
+	}
+}
+
+func TestUDP(t *testing.T) {
+	if _, err := os.Stat("input.txt"); err != nil {
+		t.Skip(err)
+	}
+
+	program, err := intcode.LoadProgram("input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	partOne, partTwo, err := runUDP(program, 50, 0, 0, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantOne, wantTwo := Solve("input.txt")
+	aoctest.Check(t, "part one", partOne, wantOne)
+	aoctest.Check(t, "part two", partTwo, wantTwo)
+}
+
+func TestTraceReceived(t *testing.T) {
+	trace := makeTrace(2, nil)
+
+	trace.packet(1, 0, 1, Packet{1, 2})
+	trace.packet(1, 0, natAddress, Packet{3, 4})
+	if trace.computers[1].Received != 0 {
+		t.Errorf("computer 1 received %d packets before reading them", trace.computers[1].Received)
+	}
+	if trace.nat.Received != 1 {
+		t.Errorf("the NAT received %d packets, want 1", trace.nat.Received)
+	}
+
+	trace.idlePoll(1)
+	trace.idlePoll(1)
+	if stats := trace.computers[1]; stats.Received != 1 || stats.IdlePolls != 2 {
+		t.Errorf("computer 1 received %d packets in %d polls, want 1 in 2", stats.Received, stats.IdlePolls)
+	}
+	if stats := trace.computers[0]; stats.Sent != 2 || stats.Received != 0 {
+		t.Errorf("computer 0 sent %d and received %d packets, want 2 and 0", stats.Sent, stats.Received)
+	}
+}
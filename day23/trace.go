@@ -0,0 +1,125 @@
+package day23
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Tracing for the simulated network (see -trace and -stats flags). A round is
+// one iteration of the main loop, in which every computer runs until it has
+// tried to receive a packet twice in a row without success.
+
+type ComputerStats struct {
+	Sent, Received, IdlePolls int
+}
+
+type Wakeup struct {
+	Round  int
+	Packet Packet
+}
+
+type Trace struct {
+	// If not nil, every packet is written to the log.
+	log *bufio.Writer
+
+	computers []ComputerStats
+	nat       ComputerStats
+	wakeups   []Wakeup
+
+	// Number of packets that have been written to the input of each
+	// computer, but not yet read by it.
+	queued []int
+
+	// The packet that the NAT did not send, because its y value has already
+	// been delivered.
+	repeated *Wakeup
+}
+
+func makeTrace(computers int, log io.Writer) *Trace {
+	trace := &Trace{
+		computers: make([]ComputerStats, computers),
+		queued:    make([]int, computers),
+	}
+
+	if log != nil {
+		trace.log = bufio.NewWriter(log)
+		// The format resembles the output of tcpdump, but uses the round
+		// instead of a timestamp.
+		fmt.Fprintln(trace.log, "# round source > destination: x y")
+	}
+
+	return trace
+}
+
+func (trace *Trace) stats(address int64) *ComputerStats {
+	if address == natAddress {
+		return &trace.nat
+	}
+	return &trace.computers[address]
+}
+
+// A packet is received when its destination reads it, which the NAT does
+// immediately (see idlePoll for the computers).
+func (trace *Trace) packet(round int, source, destination int64, packet Packet) {
+	trace.stats(source).Sent++
+	if destination == natAddress {
+		trace.nat.Received++
+	} else {
+		trace.queued[destination]++
+	}
+
+	if trace.log != nil {
+		fmt.Fprintf(trace.log, "%6d %3d > %3d: x=%d y=%d\n", round, source, destination, packet.X, packet.Y)
+	}
+}
+
+// A computer only polls once it has read all of its input, so all queued
+// packets have been received by then.
+func (trace *Trace) idlePoll(address int64) {
+	stats := trace.stats(address)
+	stats.Received += trace.queued[address]
+	stats.IdlePolls++
+	trace.queued[address] = 0
+}
+
+func (trace *Trace) wakeup(round int, packet Packet) {
+	trace.wakeups = append(trace.wakeups, Wakeup{round, packet})
+	trace.packet(round, natAddress, 0, packet)
+}
+
+// Records the packet that ends the simulation. It is not sent, so it is not
+// counted as a packet.
+func (trace *Trace) repeat(round int, packet Packet) {
+	trace.repeated = &Wakeup{round, packet}
+}
+
+func (trace *Trace) flush() error {
+	if trace.log != nil {
+		return trace.log.Flush()
+	}
+	return nil
+}
+
+func (trace *Trace) print(w io.Writer, rounds int) {
+	fmt.Fprintln(w, "----------------")
+
+	fmt.Fprintln(w, "Address   Sent  Received  Idle polls")
+	for address, stats := range trace.computers {
+		fmt.Fprintf(w, "%7d %6d %9d %11d\n", address, stats.Sent, stats.Received, stats.IdlePolls)
+	}
+	fmt.Fprintf(w, "%7d %6d %9d %11s\n", natAddress, trace.nat.Sent, trace.nat.Received, "-")
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "NAT wake-ups:")
+
+	for index, wakeup := range trace.wakeups {
+		fmt.Fprintf(w, "%4d. round %6d: x=%d y=%d\n", index+1, wakeup.Round, wakeup.Packet.X, wakeup.Packet.Y)
+	}
+	if trace.repeated != nil {
+		fmt.Fprintf(w, "      round %6d: x=%d y=%d (not sent, y has already been delivered)\n", trace.repeated.Round, trace.repeated.Packet.X, trace.repeated.Packet.Y)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "The NAT woke up the network %d times in %d rounds.\n", len(trace.wakeups), rounds)
+}
@@ -0,0 +1,333 @@
+package day23
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"greenlightning.eu/aoc19/intcode"
+)
+
+// This file contains an alternate transport for the network (see -udp flag).
+// Each computer is bound to its own port on the loopback interface and every
+// packet is sent as a UDP datagram of 16 bytes (x and y as big-endian 64-bit
+// integers). The NAT runs in its own goroutine with its own socket and
+// monitors the network to detect when it becomes idle.
+
+const natAddress = 255
+
+type Packet struct {
+	X, Y int64
+}
+
+type Network struct {
+	addresses map[int64]*net.UDPAddr
+
+	// Probabilities for dropping and delaying packets sent by the computers.
+	loss, reorder float64
+
+	// Number of packets that have been sent, but not yet consumed by their
+	// receiver. This includes delayed packets, but not dropped ones or ones
+	// that could not be written to the socket.
+	inFlight int64
+
+	// Incremented each time a packet is sent or consumed, so that the NAT can
+	// detect changes while it is checking whether the network is idle.
+	activity int64
+
+	// For each computer, non-zero if the computer has tried to receive a
+	// packet at least twice in a row without success.
+	idle []int32
+
+	// Number of packets sent by the computers, and how many of them were
+	// dropped or could not be written to the socket.
+	total, dropped, failed int64
+
+	done chan bool
+}
+
+func runUDP(program []int64, computers int, loss, reorder float64, seed int64) (partOne, partTwo string, err error) {
+	network := &Network{
+		addresses: make(map[int64]*net.UDPAddr),
+		loss:      loss,
+		reorder:   reorder,
+		idle:      make([]int32, computers),
+		done:      make(chan bool),
+	}
+
+	listen := func(address int64) *net.UDPConn {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+		check(err)
+		network.addresses[address] = conn.LocalAddr().(*net.UDPAddr)
+		return conn
+	}
+
+	// All sockets must be bound before any computer starts sending.
+	conns := make([]*net.UDPConn, computers)
+	for address := range conns {
+		conns[address] = listen(int64(address))
+	}
+	natConn := listen(natAddress)
+
+	// Every goroutine sends at most one error, so none of them blocks if
+	// the network is shut down after the first one.
+	errs := make(chan error, computers+1)
+
+	for address, conn := range conns {
+		address, conn := int64(address), conn
+		emulator := intcode.MakeEmulator(program, address)
+		random := rand.New(rand.NewSource(seed + address))
+		go func() {
+			if err := network.runComputer(address, emulator, conn, random); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	results := make(chan int64, 2)
+	go func() {
+		if err := network.runNAT(natConn, results); err != nil {
+			errs <- err
+		}
+	}()
+
+	var answers []int64
+	for len(answers) < 2 && err == nil {
+		select {
+		case answer := <-results:
+			answers = append(answers, answer)
+		case err = <-errs:
+		}
+	}
+
+	close(network.done)
+	for _, conn := range conns {
+		conn.Close()
+	}
+	natConn.Close()
+
+	if err != nil {
+		return "", "", err
+	}
+	return fmt.Sprint(answers[0]), fmt.Sprint(answers[1]), nil
+}
+
+// Returns nil when the network is shut down, or an error if the computer
+// cannot continue.
+func (network *Network) runComputer(address int64, emulator *intcode.Emulator, conn *net.UDPConn, random *rand.Rand) error {
+	packets := make(chan Packet, 1024)
+	go receive(conn, packets)
+
+	emptyPolls := 0
+
+	for {
+		select {
+		case <-network.done:
+			return nil
+		default:
+		}
+
+		destination, status := emulator.Emulate()
+		switch status {
+		case intcode.EmulatorStatusOutput:
+			x, status := emulator.Emulate()
+			if status != intcode.EmulatorStatusOutput {
+				return fmt.Errorf("computer %d: expected output", address)
+			}
+
+			y, status := emulator.Emulate()
+			if status != intcode.EmulatorStatusOutput {
+				return fmt.Errorf("computer %d: expected output", address)
+			}
+
+			// A computer that sends a packet is not idle, even if it has
+			// polled without success before.
+			atomic.StoreInt32(&network.idle[address], 0)
+			if err := network.send(conn, random, destination, Packet{x, y}); err != nil {
+				return fmt.Errorf("computer %d: %v", address, err)
+			}
+			emptyPolls = 0
+
+		case intcode.EmulatorStatusWaitingForInput:
+			var packet Packet
+			received := false
+
+			select {
+			case packet = <-packets:
+				received = true
+			default:
+				if emptyPolls >= 2 {
+					atomic.StoreInt32(&network.idle[address], 1)
+
+					// Do not spin while the computer is idle.
+					select {
+					case packet = <-packets:
+						received = true
+					case <-network.done:
+						return nil
+					case <-time.After(time.Millisecond):
+					}
+				}
+			}
+
+			if received {
+				// Clear the idle flag before the packet stops being in
+				// flight (see isIdle).
+				atomic.StoreInt32(&network.idle[address], 0)
+				emulator.Write(packet.X, packet.Y)
+				atomic.AddInt64(&network.inFlight, -1)
+				atomic.AddInt64(&network.activity, 1)
+				emptyPolls = 0
+			} else {
+				emulator.Write(-1)
+				emptyPolls++
+			}
+
+		case intcode.EmulatorStatusHalted:
+			return fmt.Errorf("computer %d halted", address)
+		}
+	}
+}
+
+// Sends the answers to both parts to results. Returns an error if the NAT
+// cannot continue, in which case the network would never finish.
+func (network *Network) runNAT(conn *net.UDPConn, results chan<- int64) error {
+	packets := make(chan Packet, 1024)
+	go receive(conn, packets)
+
+	var last Packet
+	initialized := false
+
+	delivered := make(map[int64]bool)
+
+	ticker := time.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case packet := <-packets:
+			atomic.AddInt64(&network.inFlight, -1)
+			atomic.AddInt64(&network.activity, 1)
+			if !initialized {
+				initialized = true
+				results <- packet.Y
+			}
+			last = packet
+
+		case <-ticker.C:
+			if !network.isIdle() {
+				continue
+			}
+
+			if !initialized {
+				// This can happen if packets are dropped.
+				return fmt.Errorf("network is idle, but the NAT has not received a packet yet (%d of %d packets dropped, %d failed)",
+					atomic.LoadInt64(&network.dropped), atomic.LoadInt64(&network.total), atomic.LoadInt64(&network.failed))
+			}
+
+			if delivered[last.Y] {
+				results <- last.Y
+				return nil
+			}
+			delivered[last.Y] = true
+
+			// The NAT is not under test, so its packets are never dropped or
+			// delayed.
+			atomic.AddInt64(&network.inFlight, 1)
+			atomic.AddInt64(&network.activity, 1)
+			if _, err := conn.WriteToUDP(encode(last), network.addresses[0]); err != nil {
+				atomic.AddInt64(&network.inFlight, -1)
+				return fmt.Errorf("NAT: %v", err)
+			}
+		}
+	}
+}
+
+// The network is idle if all packets have been consumed and all computers
+// are idle. The activity counter is read before and after the other values
+// to make sure that they form a consistent snapshot.
+func (network *Network) isIdle() bool {
+	activity := atomic.LoadInt64(&network.activity)
+
+	if atomic.LoadInt64(&network.inFlight) != 0 {
+		return false
+	}
+
+	for address := range network.idle {
+		if atomic.LoadInt32(&network.idle[address]) == 0 {
+			return false
+		}
+	}
+
+	return atomic.LoadInt64(&network.activity) == activity
+}
+
+func (network *Network) send(conn *net.UDPConn, random *rand.Rand, destination int64, packet Packet) error {
+	address := network.addresses[destination]
+	if address == nil {
+		return fmt.Errorf("unknown address: %d", destination)
+	}
+
+	atomic.AddInt64(&network.total, 1)
+
+	if random.Float64() < network.loss {
+		atomic.AddInt64(&network.dropped, 1)
+		return nil
+	}
+
+	atomic.AddInt64(&network.inFlight, 1)
+	atomic.AddInt64(&network.activity, 1)
+
+	data := encode(packet)
+
+	write := func() {
+		if _, err := conn.WriteToUDP(data, address); err != nil {
+			// The packet never arrives, so it must not keep the network
+			// from becoming idle.
+			atomic.AddInt64(&network.failed, 1)
+			atomic.AddInt64(&network.inFlight, -1)
+		}
+	}
+
+	if random.Float64() < network.reorder {
+		// Delay the packet, so that packets sent later overtake it.
+		delay := time.Duration(1+random.Intn(5)) * time.Millisecond
+		time.AfterFunc(delay, write)
+		return nil
+	}
+
+	write()
+	return nil
+}
+
+func receive(conn *net.UDPConn, packets chan<- Packet) {
+	buffer := make([]byte, 64)
+	for {
+		n, _, err := conn.ReadFromUDP(buffer)
+		if err != nil {
+			// The connection has been closed.
+			return
+		}
+		if n != 16 {
+			continue
+		}
+		packets <- decode(buffer[:n])
+	}
+}
+
+func encode(packet Packet) []byte {
+	data := make([]byte, 16)
+	binary.BigEndian.PutUint64(data[0:], uint64(packet.X))
+	binary.BigEndian.PutUint64(data[8:], uint64(packet.Y))
+	return data
+}
+
+func decode(data []byte) Packet {
+	return Packet{
+		X: int64(binary.BigEndian.Uint64(data[0:])),
+		Y: int64(binary.BigEndian.Uint64(data[8:])),
+	}
+}
@@ -1,32 +1,52 @@
-package main
+package day24
 
 import (
 	"bufio"
 	"flag"
 	"fmt"
+	"image"
+	"image/color"
 	"os"
+
+	"greenlightning.eu/aoc19/grid"
+	"greenlightning.eu/aoc19/render"
 )
 
 type Layer [5][5]bool
 
-var printFlag = flag.Bool("print", false, "print final state for part two")
+var Flags = flag.NewFlagSet("day24", flag.ContinueOnError)
 
-func main() {
-	flag.Parse()
+var (
+	printFlag  = Flags.Bool("print", false, "print final state for part two")
+	renderFlag = Flags.String("render", "", "render final state for part two to `file`, with the layers side by side (.png, .svg, .ans or text; - for the terminal)")
+)
 
-	lines := readLines("input.txt")
+const separator = 2
+
+var style = render.Style{
+	Palette: map[int]render.Tile{
+		0: {Glyph: '.', Color: color.RGBA{0x20, 0x20, 0x30, 0xff}},
+		1: {Glyph: '#', Color: color.RGBA{0x80, 0xe0, 0x40, 0xff}},
+		// The empty column between two layers.
+		separator: {Glyph: ' '},
+	},
+	// The recursive tile in the middle of each layer.
+	Unknown: render.Tile{Glyph: '?'},
+}
 
-	{
-		fmt.Println("--- Part One ---")
+func Solve(filename string) (partOne, partTwo string) {
+	area := grid.Parse(readLines(filename))
+	bugs := grid.FindAll(area, '#')
 
+	{
 		var state uint32
 
-		for y, line := range lines {
-			for x, char := range line {
-				if char == '#' {
-					state |= (1 << uint32(5*y+x))
-				}
-			}
+		bit := func(p image.Point) uint32 {
+			return 1 << uint32(5*p.Y+p.X)
+		}
+
+		for _, p := range bugs {
+			state |= bit(p)
 		}
 
 		seen := make(map[uint32]bool)
@@ -38,22 +58,15 @@ func main() {
 
 			for y := 0; y < 5; y++ {
 				for x := 0; x < 5; x++ {
+					p := image.Point{x, y}
 					neighbors := 0
-					if x > 0 && state&(1<<uint32(5*y+x-1)) != 0 {
-						neighbors++
-					}
-					if x < 4 && state&(1<<uint32(5*y+x+1)) != 0 {
-						neighbors++
-					}
-					if y > 0 && state&(1<<uint32(5*(y-1)+x)) != 0 {
-						neighbors++
-					}
-					if y < 4 && state&(1<<uint32(5*(y+1)+x)) != 0 {
-						neighbors++
+					for _, n := range grid.Neighbors4(area, p) {
+						if state&bit(n) != 0 {
+							neighbors++
+						}
 					}
-					var bit uint32 = 1 << uint32(5*y+x)
-					if ((state&bit != 0) && neighbors == 1) || ((state&bit == 0) && neighbors >= 1 && neighbors <= 2) {
-						next |= bit
+					if ((state&bit(p) != 0) && neighbors == 1) || ((state&bit(p) == 0) && neighbors >= 1 && neighbors <= 2) {
+						next |= bit(p)
 					}
 				}
 			}
@@ -61,141 +74,157 @@ func main() {
 			state = next
 		}
 
-		fmt.Println(state)
+		partOne = fmt.Sprint(state)
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
-
 		var layer Layer
-		for y, line := range lines {
-			for x, char := range line {
-				if char == '#' {
-					layer[y][x] = true
+		for _, p := range bugs {
+			layer[p.Y][p.X] = true
+		}
+
+		state, min, max := simulateRecursive(layer, 200)
+
+		bugs := 0
+		for _, layer := range state {
+			bugs += count(layer)
+		}
+
+		partTwo = fmt.Sprint(bugs)
+
+		if *printFlag || *renderFlag != "" {
+			for count(state[min]) == 0 && min < max {
+				min++
+			}
+			for count(state[max]) == 0 && min < max {
+				max--
+			}
+
+			// Each layer is followed by an empty column.
+			layers := render.Func(image.Rect(0, 0, (max-min+1)*6-1, 5), func(p image.Point) (int, bool) {
+				x, y := p.X%6, p.Y
+				if x == 5 {
+					return separator, true
+				}
+				if y == 2 && x == 2 {
+					return 0, false
+				}
+				if state[min+p.X/6][y][x] {
+					return 1, true
 				}
+				return 0, true
+			})
+
+			if *printFlag {
+				for index := min; index <= max; index++ {
+					fmt.Printf("Depth %d:\n", index)
+					layer := render.Func(image.Rect(0, 0, 5, 5), func(p image.Point) (int, bool) {
+						return layers.At(p.Add(image.Point{(index - min) * 6, 0}))
+					})
+					check(render.Text(os.Stdout, layer, style))
+				}
+			}
+
+			if *renderFlag != "" {
+				check(render.WriteFile(*renderFlag, layers, style))
 			}
 		}
+	}
 
-		state := make(map[int]Layer)
-		state[0] = layer
-		min, max := 0, 0
+	return partOne, partTwo
+}
 
-		for minute := 0; minute < 200; minute++ {
-			next := make(map[int]Layer)
+// Simulates the recursive grids for the given number of minutes, starting
+// with the layer at depth 0. Returns the layers by depth and the range of
+// depths that have been simulated.
+func simulateRecursive(layer Layer, minutes int) (state map[int]Layer, min, max int) {
+	state = make(map[int]Layer)
+	state[0] = layer
 
-			for index := min - 1; index <= max+1; index++ {
-				var nextLayer Layer
+	for minute := 0; minute < minutes; minute++ {
+		next := make(map[int]Layer)
 
-				for y := 0; y < 5; y++ {
-					for x := 0; x < 5; x++ {
-						if x == 2 && y == 2 {
-							continue
-						}
+		for index := min - 1; index <= max+1; index++ {
+			var nextLayer Layer
 
-						neighbors := 0
+			for y := 0; y < 5; y++ {
+				for x := 0; x < 5; x++ {
+					if x == 2 && y == 2 {
+						continue
+					}
 
-						if x > 0 && state[index][y][x-1] {
-							neighbors++
-						}
-						if x < 4 && state[index][y][x+1] {
-							neighbors++
-						}
-						if y > 0 && state[index][y-1][x] {
-							neighbors++
-						}
-						if y < 4 && state[index][y+1][x] {
-							neighbors++
-						}
+					neighbors := 0
 
-						if x == 0 && state[index-1][2][1] {
-							neighbors++
-						}
-						if x == 4 && state[index-1][2][3] {
-							neighbors++
-						}
-						if y == 0 && state[index-1][1][2] {
-							neighbors++
-						}
-						if y == 4 && state[index-1][3][2] {
-							neighbors++
-						}
+					if x > 0 && state[index][y][x-1] {
+						neighbors++
+					}
+					if x < 4 && state[index][y][x+1] {
+						neighbors++
+					}
+					if y > 0 && state[index][y-1][x] {
+						neighbors++
+					}
+					if y < 4 && state[index][y+1][x] {
+						neighbors++
+					}
+
+					if x == 0 && state[index-1][2][1] {
+						neighbors++
+					}
+					if x == 4 && state[index-1][2][3] {
+						neighbors++
+					}
+					if y == 0 && state[index-1][1][2] {
+						neighbors++
+					}
+					if y == 4 && state[index-1][3][2] {
+						neighbors++
+					}
 
-						if x == 1 && y == 2 {
-							for i := 0; i < 5; i++ {
-								if state[index+1][i][0] {
-									neighbors++
-								}
+					if x == 1 && y == 2 {
+						for i := 0; i < 5; i++ {
+							if state[index+1][i][0] {
+								neighbors++
 							}
 						}
+					}
 
-						if x == 3 && y == 2 {
-							for i := 0; i < 5; i++ {
-								if state[index+1][i][4] {
-									neighbors++
-								}
+					if x == 3 && y == 2 {
+						for i := 0; i < 5; i++ {
+							if state[index+1][i][4] {
+								neighbors++
 							}
 						}
+					}
 
-						if y == 1 && x == 2 {
-							for i := 0; i < 5; i++ {
-								if state[index+1][0][i] {
-									neighbors++
-								}
+					if y == 1 && x == 2 {
+						for i := 0; i < 5; i++ {
+							if state[index+1][0][i] {
+								neighbors++
 							}
 						}
+					}
 
-						if y == 3 && x == 2 {
-							for i := 0; i < 5; i++ {
-								if state[index+1][4][i] {
-									neighbors++
-								}
+					if y == 3 && x == 2 {
+						for i := 0; i < 5; i++ {
+							if state[index+1][4][i] {
+								neighbors++
 							}
 						}
-
-						nextLayer[y][x] = (state[index][y][x] && neighbors == 1) || (!state[index][y][x] && neighbors >= 1 && neighbors <= 2)
 					}
-				}
 
-				next[index] = nextLayer
+					nextLayer[y][x] = (state[index][y][x] && neighbors == 1) || (!state[index][y][x] && neighbors >= 1 && neighbors <= 2)
+				}
 			}
 
-			state = next
-			min, max = min-1, max+1
-		}
-
-		bugs := 0
-		for _, layer := range state {
-			bugs += count(layer)
+			next[index] = nextLayer
 		}
 
-		fmt.Println(bugs)
-
-		if *printFlag {
-			for count(state[min]) == 0 && min < max {
-				min++
-			}
-			for count(state[max]) == 0 && min < max {
-				max--
-			}
-			for index := min; index <= max; index++ {
-				layer := state[index]
-
-				fmt.Printf("Depth %d:\n", index)
-				for y := 0; y < 5; y++ {
-					for x := 0; x < 5; x++ {
-						if y == 2 && x == 2 {
-							fmt.Print("?")
-						} else if layer[y][x] {
-							fmt.Print("#")
-						} else {
-							fmt.Print(".")
-						}
-					}
-					fmt.Println()
-				}
-			}
-		}
+		state = next
+		min, max = min-1, max+1
 	}
+
+	return state, min, max
 }
 
 func count(layer Layer) (bugs int) {
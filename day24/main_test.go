@@ -0,0 +1,57 @@
+package day24
+
+import (
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+)
+
+const example = `....#
+#..#.
+#..##
+..#..
+#....
+`
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestBiodiversity(t *testing.T) {
+	partOne, _ := aoctest.Solve(t, Solve, example)
+	aoctest.Check(t, "part one", partOne, "2129920")
+}
+
+func TestRecursive(t *testing.T) {
+	var layer Layer
+	for y, line := range []string{"....#", "#..#.", "#..##", "..#..", "#...."} {
+		for x, char := range line {
+			layer[y][x] = char == '#'
+		}
+	}
+
+	state, min, max := simulateRecursive(layer, 10)
+
+	bugs := 0
+	for _, layer := range state {
+		bugs += count(layer)
+	}
+	if bugs != 99 {
+		t.Errorf("got %d bugs, want 99", bugs)
+	}
+
+	// The bugs spread by one level in each direction every other minute.
+	for count(state[min]) == 0 {
+		min++
+	}
+	for count(state[max]) == 0 {
+		max--
+	}
+	if min != -5 || max != 5 {
+		t.Errorf("got bugs in depths %d to %d, want -5 to 5", min, max)
+	}
+}
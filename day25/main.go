@@ -1,15 +1,17 @@
-package main
+package day25
 
 import (
 	"bufio"
 	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
 	"regexp"
-	"strconv"
 	"strings"
 	"time"
+
+	"greenlightning.eu/aoc19/combinatorics"
+	"greenlightning.eu/aoc19/intcode"
+	"greenlightning.eu/aoc19/search"
 )
 
 type Room struct {
@@ -27,34 +29,72 @@ const (
 
 var opposite = map[string]string{"north": "south", "south": "north", "west": "east", "east": "west"}
 
-func main() {
-	playFlag := flag.Bool("play", false, "play the game yourself")
-	interactiveFlag := flag.Bool("interactive", false, "press enter to advance")
+var Flags = flag.NewFlagSet("day25", flag.ContinueOnError)
+
+var (
+	playFlag        = Flags.Bool("play", false, "play the game yourself")
+	interactiveFlag = Flags.Bool("interactive", false, "press enter to advance")
+	recordFlag      = Flags.String("record", "", "record the session to `file`")
+	replayFlag      = Flags.String("replay", "", "replay the session from `file` and print the transcript")
+	serveFlag       = Flags.String("serve", "", "serve the game to TCP clients on `address` (e.g. localhost:2525)")
+	maxSessionsFlag = Flags.Int("max-sessions", 8, "maximum number of concurrent sessions when serving")
+	sessionLogsFlag = Flags.String("session-logs", "", "record the session of each client to a file in `directory`")
+)
+
+func Solve(filename string) (partOne, partTwo string) {
+	program, err := intcode.LoadProgram(filename)
+	check(err)
+
+	if *serveFlag != "" {
+		server := &intcode.Server{
+			Program:     program,
+			MaxSessions: *maxSessionsFlag,
+			LogDir:      *sessionLogsFlag,
+		}
+		check(server.ListenAndServe(*serveFlag))
+		return
+	}
 
-	flag.Parse()
+	if *replayFlag != "" {
+		session, err := intcode.LoadSession(*replayFlag)
+		check(err)
 
-	text := readFile("input.txt")
+		for _, event := range session.Events {
+			if event.Kind != intcode.EventHalt {
+				fmt.Print(string(rune(event.Value)))
+			}
+		}
 
-	var program []int64
-	for _, value := range strings.Split(text, ",") {
-		program = append(program, toInt64(value))
+		check(intcode.Replay(program, session))
+		fmt.Printf("Replayed %d events.\n", len(session.Events))
+		return
 	}
 
-	emulator := makeEmulator(program)
+	emulator := intcode.MakeEmulator(program)
 	scanner := bufio.NewScanner(os.Stdin)
 
+	if *recordFlag != "" {
+		// Save the session even if the explorer panics, so that the failing
+		// run can be reproduced later.
+		session := new(intcode.Session)
+		emulator.Record(session)
+		defer func() {
+			check(intcode.SaveSession(*recordFlag, session))
+		}()
+	}
+
 	if *playFlag {
 		for {
-			char, status := emulate(emulator)
+			char, status := emulator.Emulate()
 			switch status {
-			case EmulatorStatusHalted:
+			case intcode.EmulatorStatusHalted:
 				return
-			case EmulatorStatusOutput:
-				fmt.Print(string(char))
+			case intcode.EmulatorStatusOutput:
+				fmt.Print(string(rune(char)))
 				if char == '\n' {
 					time.Sleep(32 * time.Millisecond)
 				}
-			case EmulatorStatusWaitingForInput:
+			case intcode.EmulatorStatusWaitingForInput:
 				if scanner.Scan() {
 					emulator.WriteString(scanner.Text())
 					emulator.WriteString("\n")
@@ -85,7 +125,8 @@ func main() {
 	var testDir string
 
 	var availableItems []string
-	var itemMask uint64
+	var subsets *combinatorics.GraySubsets
+	var nextSubset bool
 
 	var last *Room
 	var lastItems []string
@@ -95,9 +136,9 @@ func main() {
 
 loop:
 	for {
-		char, status := emulate(emulator)
+		char, status := emulator.Emulate()
 		switch status {
-		case EmulatorStatusHalted:
+		case intcode.EmulatorStatusHalted:
 			output := outputBuilder.String()
 			outputBuilder.Reset()
 
@@ -111,25 +152,20 @@ loop:
 				}
 			}
 
-			if !*interactiveFlag {
-				fmt.Println("--- Part One ---")
-				fmt.Println(result)
-			}
+			return result, ""
 
-			return
-
-		case EmulatorStatusOutput:
+		case intcode.EmulatorStatusOutput:
 			if *interactiveFlag {
-				fmt.Print(string(char))
+				fmt.Print(string(rune(char)))
 			}
 
-			outputBuilder.WriteString(string(char))
+			outputBuilder.WriteString(string(rune(char)))
 
 			if *interactiveFlag && char == '\n' {
 				time.Sleep(32 * time.Millisecond)
 			}
 
-		case EmulatorStatusWaitingForInput:
+		case intcode.EmulatorStatusWaitingForInput:
 			output := outputBuilder.String()
 			outputBuilder.Reset()
 
@@ -318,14 +354,24 @@ loop:
 				for item := range inventory {
 					availableItems = append(availableItems, item)
 				}
-				itemMask = 0
+				// Try the combinations of items in Gray code order, so that
+				// only one item has to be taken or dropped between tests.
+				subsets = combinatorics.MakeGraySubsets(len(availableItems))
+				nextSubset = true
 				mode = ModeTest
 				fallthrough
 
 			case ModeTest:
+				if nextSubset {
+					if !subsets.Next() {
+						panic("no combination of items passes the checkpoint")
+					}
+					nextSubset = false
+				}
+
 				for index := 0; index < len(availableItems); index++ {
 					item := availableItems[index]
-					targetState := (itemMask&(1<<uint64(index)) != 0)
+					targetState := subsets.Contains(index)
 					if inventory[item] != targetState {
 						var action string
 						if targetState {
@@ -338,7 +384,7 @@ loop:
 					}
 				}
 
-				itemMask++
+				nextSubset = true
 				sendCommand("%s\n", testDir)
 				continue loop
 			}
@@ -347,202 +393,23 @@ loop:
 }
 
 func findPath(from, to *Room) []*Room {
-	type Item struct {
-		Room *Room
-		Path []*Room
-	}
-
-	var queue []Item
-	queue = append(queue, Item{from, []*Room{from}})
-
-	visited := make(map[*Room]bool)
-	visited[from] = true
-
-	for len(queue) != 0 {
-		item := queue[0]
-		queue = queue[1:]
-
-		if item.Room == to {
-			return item.Path
-		}
-
-		for _, next := range item.Room.Connections {
-			if !visited[next] {
-				visited[next] = true
-				path := make([]*Room, len(item.Path), len(item.Path)+1)
-				copy(path, item.Path)
-				path = append(path, next)
-				queue = append(queue, Item{next, path})
-			}
+	neighbors := func(node search.Node) []search.Node {
+		var result []search.Node
+		for _, next := range node.(*Room).Connections {
+			result = append(result, next)
 		}
+		return result
 	}
 
-	return nil
-}
-
-// This version of the intcode emulator does not use goroutines.
+	result := search.BFS([]search.Node{from}, neighbors, func(node search.Node) bool {
+		return node == to
+	})
 
-type EmulatorStatus int
-
-const (
-	EmulatorStatusHalted          EmulatorStatus = 0
-	EmulatorStatusOutput          EmulatorStatus = 1
-	EmulatorStatusWaitingForInput EmulatorStatus = 2
-)
-
-type Emulator struct {
-	memory           []int64
-	input            []int64
-	ip, relativeBase int64
-}
-
-func (emulator *Emulator) WriteString(s string) (int, error) {
-	for _, char := range s {
-		emulator.input = append(emulator.input, int64(char))
-	}
-	return len(s), nil
-}
-
-func makeEmulator(program []int64, input ...int64) *Emulator {
-	// Copy the program into memory, so that we do not modify the original.
-	memory := make([]int64, len(program))
-	copy(memory, program)
-
-	return &Emulator{
-		memory: memory,
-		input:  input,
-	}
-}
-
-func emulate(emulator *Emulator, input ...int64) (int64, EmulatorStatus) {
-	emulator.input = append(emulator.input, input...)
-
-	getMemoryPointer := func(index int64) *int64 {
-		// Grow memory, if index is out of range.
-		for int64(len(emulator.memory)) <= index {
-			emulator.memory = append(emulator.memory, 0)
-		}
-		return &emulator.memory[index]
-	}
-
-	for {
-		instruction := emulator.memory[emulator.ip]
-		opcode := instruction % 100
-
-		getParameter := func(offset int64) *int64 {
-			parameter := emulator.memory[emulator.ip+offset]
-			mode := instruction / pow(10, offset+1) % 10
-			switch mode {
-			case 0: // position mode
-				return getMemoryPointer(parameter)
-			case 1: // immediate mode
-				return &parameter
-			case 2: // relative mode
-				return getMemoryPointer(emulator.relativeBase + parameter)
-			default:
-				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", emulator.ip, instruction, offset, mode))
-			}
-		}
-
-		switch opcode {
-
-		case 1: // ADD
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a + *b
-			emulator.ip += 4
-
-		case 2: // MULTIPLY
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			*c = *a * *b
-			emulator.ip += 4
-
-		case 3: // INPUT
-			if len(emulator.input) == 0 {
-				return 0, EmulatorStatusWaitingForInput
-			}
-			a := getParameter(1)
-			*a = emulator.input[0]
-			emulator.input = emulator.input[1:]
-			emulator.ip += 2
-
-		case 4: // OUTPUT
-			a := getParameter(1)
-			emulator.ip += 2
-			return *a, EmulatorStatusOutput
-
-		case 5: // JUMP IF TRUE
-			a, b := getParameter(1), getParameter(2)
-			if *a != 0 {
-				emulator.ip = *b
-			} else {
-				emulator.ip += 3
-			}
-
-		case 6: // JUMP IF FALSE
-			a, b := getParameter(1), getParameter(2)
-			if *a == 0 {
-				emulator.ip = *b
-			} else {
-				emulator.ip += 3
-			}
-
-		case 7: // LESS THAN
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a < *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			emulator.ip += 4
-
-		case 8: // EQUAL
-			a, b, c := getParameter(1), getParameter(2), getParameter(3)
-			if *a == *b {
-				*c = 1
-			} else {
-				*c = 0
-			}
-			emulator.ip += 4
-
-		case 9: // RELATIVE BASE OFFSET
-			a := getParameter(1)
-			emulator.relativeBase += *a
-			emulator.ip += 2
-
-		case 99: // HALT
-			return 0, EmulatorStatusHalted
-
-		default:
-			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", emulator.ip, instruction, opcode))
-		}
-	}
-}
-
-// Integer power: compute a**b using binary powering algorithm
-// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
-// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
-func pow(a, b int64) int64 {
-	var p int64 = 1
-	for b > 0 {
-		if b&1 != 0 {
-			p *= a
-		}
-		b >>= 1
-		a *= a
+	var path []*Room
+	for _, node := range result.Path(to) {
+		path = append(path, node.(*Room))
 	}
-	return p
-}
-
-func toInt64(s string) int64 {
-	result, err := strconv.ParseInt(s, 10, 64)
-	check(err)
-	return result
-}
-
-func readFile(filename string) string {
-	bytes, err := ioutil.ReadFile(filename)
-	check(err)
-	return strings.TrimSpace(string(bytes))
+	return path
 }
 
 func check(err error) {
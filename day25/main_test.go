@@ -0,0 +1,76 @@
+package day25
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"greenlightning.eu/aoc19/aoctest"
+	"greenlightning.eu/aoc19/intcode"
+)
+
+func TestGolden(t *testing.T) {
+	aoctest.Golden(t, Solve)
+}
+
+func BenchmarkSolve(b *testing.B) {
+	aoctest.Benchmark(b, Solve)
+}
+
+func TestFindPath(t *testing.T) {
+	// hull -- hallway -- lab
+	//            |
+	//         storage -- checkpoint
+	rooms := make(map[string]*Room)
+	for _, name := range []string{"hull", "hallway", "lab", "storage", "checkpoint"} {
+		rooms[name] = &Room{Name: name, Connections: make(map[string]*Room)}
+	}
+	connect := func(a, dir, b string) {
+		rooms[a].Connections[dir] = rooms[b]
+		rooms[b].Connections[opposite[dir]] = rooms[a]
+	}
+	connect("hull", "east", "hallway")
+	connect("hallway", "east", "lab")
+	connect("hallway", "south", "storage")
+	connect("storage", "east", "checkpoint")
+
+	var names []string
+	for _, room := range findPath(rooms["lab"], rooms["checkpoint"]) {
+		names = append(names, room.Name)
+	}
+
+	if want := []string{"lab", "hallway", "storage", "checkpoint"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+}
+
+func TestRecordReplay(t *testing.T) {
+	if _, err := os.Stat("input.txt"); err != nil {
+		t.Skip(err)
+	}
+
+	dir, err := ioutil.TempDir("", "day25")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "session.txt")
+	*recordFlag = filename
+	partOne, _ := Solve("input.txt")
+	*recordFlag = ""
+
+	program, err := intcode.LoadProgram("input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := intcode.LoadSession(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := intcode.Replay(program, session); err != nil {
+		t.Errorf("replaying the session for %s: %v", partOne, err)
+	}
+}
@@ -0,0 +1,327 @@
+// Package grid stores two-dimensional maps of characters, as found in many
+// puzzle inputs, and provides the operations that the days need to walk them.
+//
+// Both grid types also implement render.Grid, with each character as the
+// value of its position, so they can be drawn directly.
+package grid
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"image"
+	"sort"
+)
+
+// A Grid provides the character at each position within its bounds. If ok is
+// false, the position is not part of the grid.
+type Grid interface {
+	Bounds() image.Rectangle
+	Lookup(p image.Point) (char byte, ok bool)
+}
+
+var (
+	offsets4 = []image.Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}}
+	offsets8 = []image.Point{{0, -1}, {1, -1}, {1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}}
+)
+
+// Neighbors4 returns the positions above, right of, below and left of p, in
+// that order, leaving out positions that are not part of the grid.
+func Neighbors4(grid Grid, p image.Point) []image.Point {
+	return neighbors(grid, p, offsets4)
+}
+
+// Neighbors8 is like Neighbors4, but includes the diagonal neighbors. They
+// are returned in clockwise order, starting with the one above p.
+func Neighbors8(grid Grid, p image.Point) []image.Point {
+	return neighbors(grid, p, offsets8)
+}
+
+func neighbors(grid Grid, p image.Point, offsets []image.Point) []image.Point {
+	result := make([]image.Point, 0, len(offsets))
+	for _, offset := range offsets {
+		if _, ok := grid.Lookup(p.Add(offset)); ok {
+			result = append(result, p.Add(offset))
+		}
+	}
+	return result
+}
+
+// FindAll returns the positions of all occurrences of char in row-major
+// order.
+func FindAll(grid Grid, char byte) []image.Point {
+	var result []image.Point
+	bounds := grid.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			if c, ok := grid.Lookup(image.Point{x, y}); ok && c == char {
+				result = append(result, image.Point{x, y})
+			}
+		}
+	}
+	return result
+}
+
+// Find returns the first occurrence of char in row-major order.
+func Find(grid Grid, char byte) (image.Point, bool) {
+	if all := FindAll(grid, char); len(all) != 0 {
+		return all[0], true
+	}
+	return image.Point{}, false
+}
+
+// FloodFill walks from start to all positions that can be reached through
+// passable characters (including start itself, regardless of its
+// character), moving horizontally and vertically. It returns the length of
+// the shortest walk to each reached position.
+func FloodFill(grid Grid, start image.Point, passable func(char byte) bool) map[image.Point]int {
+	distances := map[image.Point]int{start: 0}
+	open := []image.Point{start}
+	for len(open) != 0 {
+		current := open[0]
+		open = open[1:]
+
+		for _, next := range Neighbors4(grid, current) {
+			if _, ok := distances[next]; ok {
+				continue
+			}
+			if char, _ := grid.Lookup(next); passable(char) {
+				distances[next] = distances[current] + 1
+				open = append(open, next)
+			}
+		}
+	}
+	return distances
+}
+
+// Equal reports whether both grids have the same bounds and the same
+// characters at the same positions.
+func Equal(a, b Grid) bool {
+	bounds := a.Bounds()
+	if b.Bounds() != bounds {
+		return false
+	}
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			charA, okA := a.Lookup(image.Point{x, y})
+			charB, okB := b.Lookup(image.Point{x, y})
+			if okA != okB || charA != charB {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Hash returns a hash of the bounds and characters of the grid. Equal grids
+// have equal hashes, so the hash can be used to find repeated states.
+func Hash(grid Grid) uint64 {
+	hash := fnv.New64a()
+	bounds := grid.Bounds()
+
+	var buf [8]byte
+	for _, n := range []int{bounds.Min.X, bounds.Min.Y, bounds.Max.X, bounds.Max.Y} {
+		binary.LittleEndian.PutUint64(buf[:], uint64(n))
+		hash.Write(buf[:])
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			char, ok := grid.Lookup(image.Point{x, y})
+			if !ok {
+				// Keep missing positions apart from any character.
+				hash.Write([]byte{0, 0})
+			} else {
+				hash.Write([]byte{1, char})
+			}
+		}
+	}
+
+	return hash.Sum64()
+}
+
+// Dense is a rectangular grid with the top left corner at (0, 0). It stores
+// the characters of all positions in row-major order.
+type Dense struct {
+	Width, Height int
+	Cells         []byte
+}
+
+// MakeDense returns a grid of the given size filled with char.
+func MakeDense(width, height int, char byte) Dense {
+	grid := Dense{width, height, make([]byte, width*height)}
+	for i := range grid.Cells {
+		grid.Cells[i] = char
+	}
+	return grid
+}
+
+// Parse returns a dense grid of the lines (e.g. from readLines). Shorter lines
+// are padded with spaces.
+func Parse(lines []string) Dense {
+	width := 0
+	for _, line := range lines {
+		if len(line) > width {
+			width = len(line)
+		}
+	}
+
+	grid := MakeDense(width, len(lines), ' ')
+	for y, line := range lines {
+		copy(grid.Cells[y*width:], line)
+	}
+	return grid
+}
+
+func (grid Dense) Bounds() image.Rectangle {
+	return image.Rect(0, 0, grid.Width, grid.Height)
+}
+
+func (grid Dense) In(p image.Point) bool {
+	return p.X >= 0 && p.Y >= 0 && p.X < grid.Width && p.Y < grid.Height
+}
+
+func (grid Dense) Lookup(p image.Point) (byte, bool) {
+	if !grid.In(p) {
+		return 0, false
+	}
+	return grid.Cells[p.Y*grid.Width+p.X], true
+}
+
+// Get returns the character at p, or 0 if p is outside of the grid.
+func (grid Dense) Get(p image.Point) byte {
+	char, _ := grid.Lookup(p)
+	return char
+}
+
+func (grid Dense) Set(p image.Point, char byte) {
+	grid.Cells[p.Y*grid.Width+p.X] = char
+}
+
+// At implements render.Grid.
+func (grid Dense) At(p image.Point) (int, bool) {
+	char, ok := grid.Lookup(p)
+	return int(char), ok
+}
+
+// Row returns the characters of row y. The result shares its memory with the
+// grid.
+func (grid Dense) Row(y int) []byte {
+	return grid.Cells[y*grid.Width : (y+1)*grid.Width]
+}
+
+// Lines returns the rows of the grid as strings.
+func (grid Dense) Lines() []string {
+	lines := make([]string, grid.Height)
+	for y := range lines {
+		lines[y] = string(grid.Row(y))
+	}
+	return lines
+}
+
+func (grid Dense) Clone() Dense {
+	clone := Dense{grid.Width, grid.Height, make([]byte, len(grid.Cells))}
+	copy(clone.Cells, grid.Cells)
+	return clone
+}
+
+// Sub returns a copy of the part of the grid inside r, moved so that r.Min
+// becomes (0, 0). Positions outside of the grid are filled with spaces.
+func (grid Dense) Sub(r image.Rectangle) Dense {
+	result := MakeDense(r.Dx(), r.Dy(), ' ')
+	for y := 0; y < result.Height; y++ {
+		for x := 0; x < result.Width; x++ {
+			if char, ok := grid.Lookup(r.Min.Add(image.Point{x, y})); ok {
+				result.Set(image.Point{x, y}, char)
+			}
+		}
+	}
+	return result
+}
+
+// Sparse is a grid that only stores some positions, e.g. the interesting
+// characters of an input or an area that is discovered step by step. Its
+// bounds are the smallest rectangle that contains all of them.
+type Sparse map[image.Point]byte
+
+// ParseSparse returns a sparse grid of the lines that leaves out all
+// positions with one of the skipped characters.
+func ParseSparse(lines []string, skip string) Sparse {
+	grid := make(Sparse)
+	for y, line := range lines {
+		for x := 0; x < len(line); x++ {
+			if !contains(skip, line[x]) {
+				grid[image.Point{x, y}] = line[x]
+			}
+		}
+	}
+	return grid
+}
+
+func contains(chars string, char byte) bool {
+	for i := 0; i < len(chars); i++ {
+		if chars[i] == char {
+			return true
+		}
+	}
+	return false
+}
+
+func (grid Sparse) Bounds() image.Rectangle {
+	var bounds image.Rectangle
+	for p := range grid {
+		bounds = Extend(bounds, p)
+	}
+	return bounds
+}
+
+// Extend returns the smallest rectangle that contains the bounds and the
+// cell at p. The zero rectangle is empty, so extending it with each position
+// of a sparse map gives the bounds of the map.
+func Extend(bounds image.Rectangle, p image.Point) image.Rectangle {
+	return bounds.Union(image.Rectangle{p, p.Add(image.Point{1, 1})})
+}
+
+func (grid Sparse) Lookup(p image.Point) (byte, bool) {
+	char, ok := grid[p]
+	return char, ok
+}
+
+// At implements render.Grid.
+func (grid Sparse) At(p image.Point) (int, bool) {
+	char, ok := grid[p]
+	return int(char), ok
+}
+
+// Positions returns all stored positions in row-major order.
+func (grid Sparse) Positions() []image.Point {
+	result := make([]image.Point, 0, len(grid))
+	for p := range grid {
+		result = append(result, p)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Y != result[j].Y {
+			return result[i].Y < result[j].Y
+		}
+		return result[i].X < result[j].X
+	})
+	return result
+}
+
+func (grid Sparse) Clone() Sparse {
+	clone := make(Sparse, len(grid))
+	for p, char := range grid {
+		clone[p] = char
+	}
+	return clone
+}
+
+// Sub returns the positions inside r, keeping their coordinates.
+func (grid Sparse) Sub(r image.Rectangle) Sparse {
+	result := make(Sparse)
+	for p, char := range grid {
+		if p.In(r) {
+			result[p] = char
+		}
+	}
+	return result
+}
@@ -0,0 +1,121 @@
+package grid
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+var maze = []string{
+	"#####",
+	"#a.##",
+	"#.#.#",
+	"#..b#",
+	"#####",
+}
+
+func TestParse(t *testing.T) {
+	grid := Parse([]string{"ab", "c", "def"})
+	if grid.Width != 3 || grid.Height != 3 {
+		t.Fatalf("size = %dx%d, want 3x3", grid.Width, grid.Height)
+	}
+	if want := []string{"ab ", "c  ", "def"}; !reflect.DeepEqual(grid.Lines(), want) {
+		t.Errorf("Lines() = %q, want %q", grid.Lines(), want)
+	}
+	if char, ok := grid.Lookup(image.Point{3, 0}); ok || char != 0 {
+		t.Errorf("Lookup outside = %q, %v", char, ok)
+	}
+}
+
+func TestNeighbors(t *testing.T) {
+	grid := Parse(maze)
+
+	corner := Neighbors4(grid, image.Point{0, 0})
+	if want := []image.Point{{1, 0}, {0, 1}}; !reflect.DeepEqual(corner, want) {
+		t.Errorf("Neighbors4 of corner = %v, want %v", corner, want)
+	}
+	if n := len(Neighbors8(grid, image.Point{0, 0})); n != 3 {
+		t.Errorf("Neighbors8 of corner has %d positions, want 3", n)
+	}
+	if n := len(Neighbors8(grid, image.Point{2, 2})); n != 8 {
+		t.Errorf("Neighbors8 of center has %d positions, want 8", n)
+	}
+
+	sparse := ParseSparse(maze, "#")
+	if got := Neighbors4(sparse, image.Point{1, 1}); !reflect.DeepEqual(got, []image.Point{{2, 1}, {1, 2}}) {
+		t.Errorf("sparse Neighbors4 = %v", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	grid := Parse(maze)
+
+	if p, ok := Find(grid, 'b'); !ok || p != (image.Point{3, 3}) {
+		t.Errorf("Find(b) = %v, %v", p, ok)
+	}
+	if _, ok := Find(grid, 'c'); ok {
+		t.Error("Find(c) found something")
+	}
+	if n := len(FindAll(grid, '.')); n != 5 {
+		t.Errorf("FindAll(.) found %d positions, want 5", n)
+	}
+	if got := FindAll(grid, '#')[5]; got != (image.Point{0, 1}) {
+		t.Errorf("FindAll is not in row-major order, sixth is %v", got)
+	}
+}
+
+func TestFloodFill(t *testing.T) {
+	grid := Parse(maze)
+	start, _ := Find(grid, 'a')
+
+	distances := FloodFill(grid, start, func(char byte) bool {
+		return char != '#'
+	})
+	if len(distances) != 7 {
+		t.Errorf("reached %d positions, want 7", len(distances))
+	}
+	if d := distances[image.Point{3, 3}]; d != 4 {
+		t.Errorf("distance to b = %d, want 4", d)
+	}
+	if d := distances[image.Point{3, 2}]; d != 5 {
+		t.Errorf("distance to dead end = %d, want 5", d)
+	}
+}
+
+func TestSub(t *testing.T) {
+	grid := Parse(maze)
+
+	sub := grid.Sub(image.Rect(3, 3, 6, 5))
+	if want := []string{"b# ", "## "}; !reflect.DeepEqual(sub.Lines(), want) {
+		t.Errorf("Sub = %q, want %q", sub.Lines(), want)
+	}
+
+	sparse := ParseSparse(maze, "#.").Sub(image.Rect(0, 0, 3, 3))
+	if !reflect.DeepEqual(sparse, Sparse{{1, 1}: 'a'}) {
+		t.Errorf("sparse Sub = %v", sparse)
+	}
+}
+
+func TestEqualAndHash(t *testing.T) {
+	a := Parse(maze)
+	b := a.Clone()
+	if !Equal(a, b) || Hash(a) != Hash(b) {
+		t.Error("clone is not equal")
+	}
+
+	b.Set(image.Point{2, 1}, '#')
+	if Equal(a, b) || Hash(a) == Hash(b) {
+		t.Error("modified clone is still equal")
+	}
+	if a.Get(image.Point{2, 1}) != '.' {
+		t.Error("modifying the clone changed the original")
+	}
+
+	// A sparse grid with all positions is equal to the dense one.
+	if sparse := ParseSparse(maze, ""); !Equal(a, sparse) || Hash(a) != Hash(sparse) {
+		t.Error("sparse grid is not equal to dense grid")
+	}
+	if sparse := ParseSparse(maze, "."); Equal(a, sparse) || Hash(a) == Hash(sparse) {
+		t.Error("sparse grid with missing positions is equal to dense grid")
+	}
+}
@@ -0,0 +1,202 @@
+// Package input finds the puzzle input of a day. The input is taken from an
+// explicit file, from stdin, from a cache directory, or downloaded from the
+// Advent of Code website (or any server with the same URLs), in which case it
+// is stored in the cache directory for the next time.
+package input
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultBaseURL = "https://adventofcode.com"
+	DefaultYear    = 2019
+)
+
+// SessionEnv is the environment variable that is used for the session cookie
+// if no session is given explicitly.
+const SessionEnv = "AOC_SESSION"
+
+// ErrMissing is returned (wrapped in a more detailed error) if the input is
+// not in the cache and cannot be downloaded, because no session is set.
+var ErrMissing = errors.New("input not found")
+
+// A Source describes where to look for the input. The zero value looks only in
+// the cache of the current directory.
+type Source struct {
+	// If Path is set, the input is read from this file and all other
+	// settings are ignored. The path "-" means stdin.
+	Path  string
+	Stdin io.Reader
+
+	// The input of day N is cached in CacheDir/dayNN/input.txt, which is the
+	// layout of this repository. An empty directory means the current
+	// directory.
+	CacheDir string
+
+	// If the input is not cached and Session is set, the input is downloaded
+	// from BaseURL/Year/day/N/input using Session as the session cookie.
+	BaseURL string
+	Year    int
+	Session string
+	Client  *http.Client
+
+	// Failed downloads are retried this many times after a delay, which
+	// doubles after each attempt. Client errors (like an invalid session) are
+	// not retried.
+	Retries    int
+	RetryDelay time.Duration
+}
+
+// Resolve returns the name of a file that contains the input of the day. If
+// the input is read from stdin, it is copied to a temporary file, which is
+// removed by cleanup. Cleanup must always be called when err is nil.
+func (s *Source) Resolve(day int) (filename string, cleanup func(), err error) {
+	cleanup = func() {}
+
+	if s.Path == "-" {
+		stdin := s.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		return copyToTemp(stdin)
+	}
+
+	if s.Path != "" {
+		if _, err := os.Stat(s.Path); err != nil {
+			return "", nil, err
+		}
+		return s.Path, cleanup, nil
+	}
+
+	filename = s.CachePath(day)
+	if _, err := os.Stat(filename); err == nil {
+		return filename, cleanup, nil
+	} else if !os.IsNotExist(err) {
+		return "", nil, err
+	}
+
+	if s.Session == "" {
+		return "", nil, fmt.Errorf("day %d: %w: %s does not exist and no session is set to download it (set %s)", day, ErrMissing, filename, SessionEnv)
+	}
+
+	data, err := s.Download(day)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return "", nil, err
+	}
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return "", nil, err
+	}
+
+	return filename, cleanup, nil
+}
+
+// CachePath returns the name of the file in which the input of the day is
+// cached.
+func (s *Source) CachePath(day int) string {
+	return filepath.Join(s.CacheDir, fmt.Sprintf("day%02d", day), "input.txt")
+}
+
+// Download fetches the input of the day from the server, without using the
+// cache.
+func (s *Source) Download(day int) ([]byte, error) {
+	baseURL := strings.TrimRight(s.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	year := s.Year
+	if year == 0 {
+		year = DefaultYear
+	}
+	url := fmt.Sprintf("%s/%d/day/%d/input", baseURL, year, day)
+
+	delay := s.RetryDelay
+	for attempt := 0; ; attempt++ {
+		data, retry, err := s.get(url)
+		if err == nil {
+			return data, nil
+		}
+		if !retry || attempt >= s.Retries {
+			return nil, fmt.Errorf("day %d: downloading %s: %w", day, url, err)
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// Returns whether the request should be retried if it failed.
+func (s *Source) get(url string) (data []byte, retry bool, err error) {
+	request, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	request.Header.Set("User-Agent", "github.com/GreenLightning/aoc19")
+	request.AddCookie(&http.Cookie{Name: "session", Value: s.Session})
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, true, err
+	}
+	defer response.Body.Close()
+
+	data, err = ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, true, err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusOK:
+		if len(data) == 0 {
+			return nil, false, errors.New("empty response")
+		}
+		return data, false, nil
+
+	case response.StatusCode == http.StatusNotFound:
+		return nil, false, errors.New("404 Not Found (the puzzle may not be unlocked yet)")
+
+	case response.StatusCode == http.StatusBadRequest || response.StatusCode == http.StatusUnauthorized:
+		return nil, false, fmt.Errorf("%s (the session is probably invalid or expired)", response.Status)
+
+	case response.StatusCode >= 500:
+		return nil, true, errors.New(response.Status)
+
+	default:
+		return nil, false, errors.New(response.Status)
+	}
+}
+
+func copyToTemp(reader io.Reader) (filename string, cleanup func(), err error) {
+	file, err := ioutil.TempFile("", "aoc19-input-*.txt")
+	if err != nil {
+		return "", nil, err
+	}
+	cleanup = func() { os.Remove(file.Name()) }
+
+	_, err = io.Copy(file, reader)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		cleanup()
+		return "", nil, err
+	}
+
+	return file.Name(), cleanup, nil
+}
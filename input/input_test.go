@@ -0,0 +1,110 @@
+package input
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "secret" {
+			http.Error(w, "Puzzle inputs differ by user.  Please log in to get your puzzle input.", http.StatusBadRequest)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/2019/day/1/input":
+			// Fail once to test the retry.
+			if requests == 1 {
+				http.Error(w, "busy", http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte("12\n14\n"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "input")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	source := &Source{CacheDir: dir, BaseURL: server.URL, Session: "secret", Retries: 1}
+
+	// Downloads the input and stores it in the cache.
+	filename, cleanup, err := source.Resolve(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cleanup()
+	if filename != source.CachePath(1) {
+		t.Errorf("got %s, want %s", filename, source.CachePath(1))
+	}
+	if data, _ := ioutil.ReadFile(filename); string(data) != "12\n14\n" {
+		t.Errorf("got %q in cache", data)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests, want 2", requests)
+	}
+
+	// Uses the cache.
+	if _, _, err := source.Resolve(1); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("got %d requests after cache hit, want 2", requests)
+	}
+
+	// Not found is not retried.
+	requests = 0
+	if _, _, err := source.Resolve(2); err == nil || !strings.Contains(err.Error(), "not be unlocked") {
+		t.Errorf("got error %v for missing puzzle", err)
+	}
+	if requests != 1 {
+		t.Errorf("got %d requests for missing puzzle, want 1", requests)
+	}
+
+	// Invalid session.
+	source.Session = "wrong"
+	if _, _, err := source.Resolve(3); err == nil || !strings.Contains(err.Error(), "session") {
+		t.Errorf("got error %v for invalid session", err)
+	}
+
+	// No session.
+	source.Session = ""
+	if _, _, err := source.Resolve(3); !errors.Is(err, ErrMissing) {
+		t.Errorf("got error %v without session, want ErrMissing", err)
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	source := &Source{Path: "-", Stdin: strings.NewReader("1,2,3\n")}
+	filename, cleanup, err := source.Resolve(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := ioutil.ReadFile(filename); string(data) != "1,2,3\n" {
+		t.Errorf("got %q from stdin", data)
+	}
+	cleanup()
+	if _, err := os.Stat(filename); !os.IsNotExist(err) {
+		t.Errorf("temporary file was not removed")
+	}
+
+	source = &Source{Path: "does-not-exist.txt"}
+	if _, _, err := source.Resolve(5); err == nil {
+		t.Errorf("expected an error for a missing file")
+	}
+}
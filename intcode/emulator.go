@@ -0,0 +1,207 @@
+// Package intcode contains the intcode emulator shared by the drivers that
+// need more than a single run of a program (e.g. recording a session).
+package intcode
+
+import "fmt"
+
+// This version of the intcode emulator does not use goroutines. See Run for
+// an adapter that provides the channel based interface of the other days.
+
+type EmulatorStatus int
+
+const (
+	EmulatorStatusHalted          EmulatorStatus = 0
+	EmulatorStatusOutput          EmulatorStatus = 1
+	EmulatorStatusWaitingForInput EmulatorStatus = 2
+)
+
+type Emulator struct {
+	memory           []int64
+	input            []int64
+	ip, relativeBase int64
+
+	// Number of instructions executed so far.
+	steps int64
+
+	// If not nil, all input and output values are appended to the session.
+	session *Session
+}
+
+func MakeEmulator(program []int64, input ...int64) *Emulator {
+	// Copy the program into memory, so that we do not modify the original.
+	memory := make([]int64, len(program))
+	copy(memory, program)
+
+	return &Emulator{
+		memory: memory,
+		input:  input,
+	}
+}
+
+// Clone returns an independent copy of the emulator in its current state,
+// including unconsumed input. The copy does not record to the session.
+func (emulator *Emulator) Clone() *Emulator {
+	clone := *emulator
+	clone.memory = append([]int64(nil), emulator.memory...)
+	clone.input = append([]int64(nil), emulator.input...)
+	clone.session = nil
+	return &clone
+}
+
+// Record appends all values consumed and produced by the emulator from now on
+// to the session.
+func (emulator *Emulator) Record(session *Session) {
+	emulator.session = session
+}
+
+// Steps returns the number of instructions executed so far.
+func (emulator *Emulator) Steps() int64 {
+	return emulator.steps
+}
+
+// Pending returns the number of input values that have not been consumed yet.
+func (emulator *Emulator) Pending() int {
+	return len(emulator.input)
+}
+
+func (emulator *Emulator) Write(input ...int64) {
+	emulator.input = append(emulator.input, input...)
+}
+
+func (emulator *Emulator) WriteString(s string) (int, error) {
+	for _, char := range s {
+		emulator.input = append(emulator.input, int64(char))
+	}
+	return len(s), nil
+}
+
+func (emulator *Emulator) Emulate(input ...int64) (int64, EmulatorStatus) {
+	emulator.input = append(emulator.input, input...)
+
+	getMemoryPointer := func(index int64) *int64 {
+		// Grow memory, if index is out of range.
+		for int64(len(emulator.memory)) <= index {
+			emulator.memory = append(emulator.memory, 0)
+		}
+		return &emulator.memory[index]
+	}
+
+	for {
+		instruction := emulator.memory[emulator.ip]
+		opcode := instruction % 100
+
+		getParameter := func(offset int64) *int64 {
+			parameter := emulator.memory[emulator.ip+offset]
+			mode := instruction / pow(10, offset+1) % 10
+			switch mode {
+			case 0: // position mode
+				return getMemoryPointer(parameter)
+			case 1: // immediate mode
+				return &parameter
+			case 2: // relative mode
+				return getMemoryPointer(emulator.relativeBase + parameter)
+			default:
+				panic(fmt.Sprintf("fault: invalid parameter mode: ip=%d instruction=%d offset=%d mode=%d", emulator.ip, instruction, offset, mode))
+			}
+		}
+
+		switch opcode {
+
+		case 1: // ADD
+			a, b, c := getParameter(1), getParameter(2), getParameter(3)
+			*c = *a + *b
+			emulator.ip += 4
+
+		case 2: // MULTIPLY
+			a, b, c := getParameter(1), getParameter(2), getParameter(3)
+			*c = *a * *b
+			emulator.ip += 4
+
+		case 3: // INPUT
+			if len(emulator.input) == 0 {
+				return 0, EmulatorStatusWaitingForInput
+			}
+			a := getParameter(1)
+			*a = emulator.input[0]
+			emulator.input = emulator.input[1:]
+			emulator.record(EventInput, *a)
+			emulator.ip += 2
+
+		case 4: // OUTPUT
+			a := getParameter(1)
+			emulator.record(EventOutput, *a)
+			emulator.ip += 2
+			emulator.steps++
+			return *a, EmulatorStatusOutput
+
+		case 5: // JUMP IF TRUE
+			a, b := getParameter(1), getParameter(2)
+			if *a != 0 {
+				emulator.ip = *b
+			} else {
+				emulator.ip += 3
+			}
+
+		case 6: // JUMP IF FALSE
+			a, b := getParameter(1), getParameter(2)
+			if *a == 0 {
+				emulator.ip = *b
+			} else {
+				emulator.ip += 3
+			}
+
+		case 7: // LESS THAN
+			a, b, c := getParameter(1), getParameter(2), getParameter(3)
+			if *a < *b {
+				*c = 1
+			} else {
+				*c = 0
+			}
+			emulator.ip += 4
+
+		case 8: // EQUAL
+			a, b, c := getParameter(1), getParameter(2), getParameter(3)
+			if *a == *b {
+				*c = 1
+			} else {
+				*c = 0
+			}
+			emulator.ip += 4
+
+		case 9: // RELATIVE BASE OFFSET
+			a := getParameter(1)
+			emulator.relativeBase += *a
+			emulator.ip += 2
+
+		case 99: // HALT
+			emulator.record(EventHalt, 0)
+			return 0, EmulatorStatusHalted
+
+		default:
+			panic(fmt.Sprintf("fault: invalid opcode: ip=%d instruction=%d opcode=%d", emulator.ip, instruction, opcode))
+		}
+
+		emulator.steps++
+	}
+}
+
+func (emulator *Emulator) record(kind EventKind, value int64) {
+	if emulator.session != nil {
+		emulator.session.Events = append(emulator.session.Events, Event{kind, emulator.steps, value})
+	}
+}
+
+// Integer power: compute a**b using binary powering algorithm
+// See Donald Knuth, The Art of Computer Programming, Volume 2, Section 4.6.3
+// Source: https://groups.google.com/d/msg/golang-nuts/PnLnr4bc9Wo/z9ZGv2DYxXoJ
+func pow(a, b int64) int64 {
+	var p int64 = 1
+	for b > 0 {
+		if b&1 != 0 {
+			p *= a
+		}
+		b >>= 1
+		a *= a
+	}
+	return p
+}
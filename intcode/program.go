@@ -0,0 +1,193 @@
+package intcode
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strconv"
+)
+
+// Programs can be stored in the following formats:
+//
+// Text: Comma-separated decimal numbers. Spaces, tabs and newlines are
+// allowed between numbers and commas, a trailing comma is ignored and a "#"
+// starts a comment that extends to the end of the line.
+//
+// Binary: The magic bytes "ICVB" followed by the values of the program, each
+// encoded as a signed varint (see encoding/binary).
+//
+// Both formats may additionally be compressed with gzip.
+
+var binaryMagic = []byte("ICVB")
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// A SyntaxError reports a malformed value in a text program.
+type SyntaxError struct {
+	Filename     string
+	Line, Column int
+	Message      string
+}
+
+func (e *SyntaxError) Error() string {
+	if e.Filename == "" {
+		return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Message)
+	}
+	return fmt.Sprintf("%s:%d:%d: %s", e.Filename, e.Line, e.Column, e.Message)
+}
+
+// LoadProgram reads a program in any of the supported formats from a file.
+func LoadProgram(filename string) ([]int64, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := DecodeProgram(data)
+	if err != nil {
+		if syntaxErr, ok := err.(*SyntaxError); ok {
+			syntaxErr.Filename = filename
+			return nil, syntaxErr
+		}
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+
+	return program, nil
+}
+
+// DecodeProgram detects the format of the data and decodes the program.
+func DecodeProgram(data []byte) ([]int64, error) {
+	if bytes.HasPrefix(data, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+
+		uncompressed, err := ioutil.ReadAll(reader)
+		if err != nil {
+			return nil, err
+		}
+
+		return DecodeProgram(uncompressed)
+	}
+
+	if bytes.HasPrefix(data, binaryMagic) {
+		return decodeBinary(data[len(binaryMagic):])
+	}
+
+	return ParseProgram(string(data))
+}
+
+// ParseProgram parses a program in the text format.
+func ParseProgram(text string) ([]int64, error) {
+	var program []int64
+
+	line, column := 1, 1
+	expectValue := true
+
+	for i := 0; i < len(text); {
+		char := text[i]
+		switch {
+		case char == '\n':
+			line, column = line+1, 1
+			i++
+
+		case char == ' ' || char == '\t' || char == '\r':
+			column++
+			i++
+
+		case char == '#':
+			for i < len(text) && text[i] != '\n' {
+				i++
+			}
+
+		case char == ',':
+			if expectValue {
+				return nil, &SyntaxError{Line: line, Column: column, Message: "unexpected ','"}
+			}
+			expectValue = true
+			column++
+			i++
+
+		default:
+			start := i
+			for i < len(text) && !isSeparator(text[i]) {
+				i++
+			}
+			token := text[start:i]
+
+			if !expectValue {
+				return nil, &SyntaxError{Line: line, Column: column, Message: fmt.Sprintf("expected ',' before %q", token)}
+			}
+
+			value, err := strconv.ParseInt(token, 10, 64)
+			if err != nil {
+				message := fmt.Sprintf("invalid number %q", token)
+				if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+					message = fmt.Sprintf("number %q out of range", token)
+				}
+				return nil, &SyntaxError{Line: line, Column: column, Message: message}
+			}
+
+			program = append(program, value)
+			expectValue = false
+			column += len(token)
+		}
+	}
+
+	if len(program) == 0 {
+		return nil, &SyntaxError{Line: line, Column: column, Message: "empty program"}
+	}
+
+	return program, nil
+}
+
+func isSeparator(char byte) bool {
+	return char == ',' || char == ' ' || char == '\t' || char == '\r' || char == '\n' || char == '#'
+}
+
+func decodeBinary(data []byte) ([]int64, error) {
+	var program []int64
+
+	offset := len(binaryMagic)
+	for len(data) != 0 {
+		value, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("offset %d: invalid varint", offset)
+		}
+		program = append(program, value)
+		data = data[n:]
+		offset += n
+	}
+
+	if len(program) == 0 {
+		return nil, fmt.Errorf("empty program")
+	}
+
+	return program, nil
+}
+
+// EncodeBinary writes the program in the binary format. Wrap w in a
+// gzip.Writer to produce a compressed file.
+func EncodeBinary(w io.Writer, program []int64) error {
+	writer := bufio.NewWriter(w)
+
+	if _, err := writer.Write(binaryMagic); err != nil {
+		return err
+	}
+
+	buffer := make([]byte, binary.MaxVarintLen64)
+	for _, value := range program {
+		n := binary.PutVarint(buffer, value)
+		if _, err := writer.Write(buffer[:n]); err != nil {
+			return err
+		}
+	}
+
+	return writer.Flush()
+}
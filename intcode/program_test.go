@@ -0,0 +1,189 @@
+package intcode
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseProgram(t *testing.T) {
+	tests := []struct {
+		name, text string
+		program    []int64
+	}{
+		{"plain", "1,9,10,3,2,3,11,0,99,30,40,50", []int64{1, 9, 10, 3, 2, 3, 11, 0, 99, 30, 40, 50}},
+		{"newline at end", "104,-5,99\n", []int64{104, -5, 99}},
+		{"whitespace", " 1 ,\t2,\r\n3 , 4\n", []int64{1, 2, 3, 4}},
+		{"trailing comma", "1,2,3,\n", []int64{1, 2, 3}},
+		{"comments", "# header\n1, 2, # first\n3 # second\n# end", []int64{1, 2, 3}},
+		{"comment after value", "1#comment,2\n,2", []int64{1, 2}},
+		{"large values", "9223372036854775807,-9223372036854775808", []int64{9223372036854775807, -9223372036854775808}},
+	}
+
+	for _, test := range tests {
+		program, err := ParseProgram(test.text)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(program, test.program) {
+			t.Errorf("%s: got %v, want %v", test.name, program, test.program)
+		}
+	}
+}
+
+func TestParseProgramErrors(t *testing.T) {
+	tests := []struct {
+		name, text   string
+		line, column int
+		message      string
+	}{
+		{"empty", "", 1, 1, "empty program"},
+		{"only comments", "# nothing\n\n", 3, 1, "empty program"},
+		{"leading comma", ",1", 1, 1, "unexpected ','"},
+		{"double comma", "1,2,,3", 1, 5, "unexpected ','"},
+		{"missing comma", "1,2,\n3 4", 2, 3, `expected ',' before "4"`},
+		{"missing comma after newline", "1\n2", 2, 1, `expected ',' before "2"`},
+		{"invalid number", "1,\n  2x,3", 2, 3, `invalid number "2x"`},
+		{"out of range", "1,99999999999999999999", 1, 3, `number "99999999999999999999" out of range`},
+	}
+
+	for _, test := range tests {
+		_, err := ParseProgram(test.text)
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%s: got error %v, want a syntax error", test.name, err)
+			continue
+		}
+		want := SyntaxError{Line: test.line, Column: test.column, Message: test.message}
+		if *syntaxErr != want {
+			t.Errorf("%s: got %v, want %v", test.name, syntaxErr, &want)
+		}
+	}
+}
+
+func TestBinaryFormat(t *testing.T) {
+	program := []int64{1, -1, 0, 63, -64, 64, 1 << 40, -9223372036854775808, 99}
+
+	var buffer bytes.Buffer
+	if err := EncodeBinary(&buffer, program); err != nil {
+		t.Fatal(err)
+	}
+
+	data := buffer.Bytes()
+	if !bytes.HasPrefix(data, []byte("ICVB")) {
+		t.Fatalf("missing magic bytes: %q", data[:4])
+	}
+	// Small values take a single byte: 1 is encoded as 2 and -1 as 1.
+	if !bytes.Equal(data[4:7], []byte{2, 1, 0}) {
+		t.Errorf("got encoding % x for 1, -1, 0", data[4:7])
+	}
+
+	decoded, err := DecodeProgram(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, program) {
+		t.Errorf("got %v, want %v", decoded, program)
+	}
+}
+
+func TestBinaryFormatErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  string
+	}{
+		{"empty", []byte("ICVB"), "empty program"},
+		// A varint whose last byte has the continuation bit set.
+		{"truncated", []byte("ICVB\x02\x80"), "offset 5: invalid varint"},
+	}
+
+	for _, test := range tests {
+		_, err := DecodeProgram(test.data)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+		}
+	}
+}
+
+func compress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buffer bytes.Buffer
+	writer := gzip.NewWriter(&buffer)
+	if _, err := writer.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestGzip(t *testing.T) {
+	program := []int64{3, 0, 4, 0, 99}
+
+	var binary bytes.Buffer
+	if err := EncodeBinary(&binary, program); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"text", compress(t, []byte("3,0,4,0,99\n"))},
+		{"binary", compress(t, binary.Bytes())},
+	}
+
+	for _, test := range tests {
+		decoded, err := DecodeProgram(test.data)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, program) {
+			t.Errorf("%s: got %v, want %v", test.name, decoded, program)
+		}
+	}
+
+	// The header is valid, but the data is cut off.
+	data := compress(t, []byte("3,0,4,0,99\n"))
+	if _, err := DecodeProgram(data[:len(data)-4]); err == nil {
+		t.Errorf("truncated gzip data: got no error")
+	}
+}
+
+func TestLoadProgram(t *testing.T) {
+	dir, err := ioutil.TempDir("", "intcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "input.txt")
+	if err := ioutil.WriteFile(filename, []byte("1,2,\n3,,4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Syntax errors are reported with the name of the file.
+	_, err = LoadProgram(filename)
+	if want := filename + ":2:3: unexpected ','"; err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+
+	if err := ioutil.WriteFile(filename, compress(t, []byte("1,2,3\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+	program, err := LoadProgram(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int64{1, 2, 3}; !reflect.DeepEqual(program, want) {
+		t.Errorf("got %v, want %v", program, want)
+	}
+}
@@ -0,0 +1,19 @@
+package intcode
+
+// Run provides the channel based interface used by most drivers on top of the
+// emulator. It reads from input whenever the program requests a value, sends
+// every output value to output and signals halt once the program halts.
+func Run(emulator *Emulator, input <-chan int64, output chan<- int64, halt chan<- bool) {
+	for {
+		value, status := emulator.Emulate()
+		switch status {
+		case EmulatorStatusHalted:
+			halt <- true
+			return
+		case EmulatorStatusOutput:
+			output <- value
+		case EmulatorStatusWaitingForInput:
+			emulator.Write(<-input)
+		}
+	}
+}
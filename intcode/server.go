@@ -0,0 +1,203 @@
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// A Handler connects a freshly started emulator to a client. It returns once
+// the program halts or the client disconnects.
+type Handler func(emulator *Emulator, client io.ReadWriter) error
+
+// A Server runs a fresh emulator for each TCP connection.
+type Server struct {
+	Program []int64
+
+	// Handler is used for each connection. If nil, ServeASCII is used.
+	Handler Handler
+
+	// MaxSessions limits the number of concurrent sessions. Connections
+	// beyond the limit are rejected. Zero means no limit.
+	MaxSessions int
+
+	// If LogDir is not empty, the session of each connection is recorded to
+	// a file in this directory, which can be replayed with Replay.
+	LogDir string
+
+	mutex    sync.Mutex
+	sessions int
+	counter  int
+}
+
+func (s *Server) ListenAndServe(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	defer listener.Close()
+
+	log.Printf("listening on %s", listener.Addr())
+	return s.Serve(listener)
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	if s.LogDir != "" {
+		if err := os.MkdirAll(s.LogDir, 0755); err != nil {
+			return err
+		}
+	}
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+
+		id, ok := s.acquire()
+		if !ok {
+			log.Printf("rejected %s: too many sessions", conn.RemoteAddr())
+			fmt.Fprintf(conn, "Too many sessions, try again later.\n")
+			conn.Close()
+			continue
+		}
+
+		go func() {
+			defer s.release()
+			defer conn.Close()
+			s.handle(id, conn)
+		}()
+	}
+}
+
+func (s *Server) acquire() (id int, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.MaxSessions > 0 && s.sessions >= s.MaxSessions {
+		return 0, false
+	}
+
+	s.sessions++
+	s.counter++
+	return s.counter, true
+}
+
+func (s *Server) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sessions--
+}
+
+func (s *Server) handle(id int, conn net.Conn) {
+	start := time.Now()
+	log.Printf("session %d: connected from %s", id, conn.RemoteAddr())
+
+	emulator := MakeEmulator(s.Program)
+
+	session := new(Session)
+	emulator.Record(session)
+
+	handler := s.Handler
+	if handler == nil {
+		handler = ServeASCII
+	}
+
+	err := func() (err error) {
+		// A faulting program must not take down the whole server.
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("%v", r)
+			}
+		}()
+		return handler(emulator, conn)
+	}()
+
+	if err != nil && err != io.EOF {
+		log.Printf("session %d: %v", id, err)
+	}
+
+	if s.LogDir != "" {
+		filename := filepath.Join(s.LogDir, fmt.Sprintf("session-%04d.txt", id))
+		if err := s.saveLog(filename, conn, start, session); err != nil {
+			log.Printf("session %d: %v", id, err)
+		}
+	}
+
+	log.Printf("session %d: closed after %d steps and %v", id, emulator.Steps(), time.Since(start).Round(time.Millisecond))
+}
+
+func (s *Server) saveLog(filename string, conn net.Conn, start time.Time, session *Session) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(file, "# remote %s\n", conn.RemoteAddr())
+	fmt.Fprintf(file, "# started %s\n", start.Format(time.RFC3339))
+
+	if _, err := session.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// ServeASCII streams the output of the program to the client as text and
+// sends each line received from the client to the program. Output values
+// outside of the ASCII range are printed as decimal numbers on their own
+// line.
+func ServeASCII(emulator *Emulator, client io.ReadWriter) error {
+	reader := bufio.NewReader(client)
+	writer := bufio.NewWriter(client)
+
+	for {
+		value, status := emulator.Emulate()
+		switch status {
+		case EmulatorStatusHalted:
+			return writer.Flush()
+
+		case EmulatorStatusOutput:
+			if value >= 0 && value < 128 {
+				writer.WriteByte(byte(value))
+			} else {
+				fmt.Fprintf(writer, "\n%d\n", value)
+			}
+
+		case EmulatorStatusWaitingForInput:
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+
+			line, err := ReadLine(reader)
+			if err != nil {
+				return err
+			}
+
+			emulator.WriteString(line)
+			emulator.WriteString("\n")
+		}
+	}
+}
+
+// ReadLine reads a line from the client and strips the line ending. A final
+// line without line ending is returned before io.EOF is reported.
+func ReadLine(reader *bufio.Reader) (string, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	for len(line) != 0 && (line[len(line)-1] == '\n' || line[len(line)-1] == '\r') {
+		line = line[:len(line)-1]
+	}
+
+	return line, nil
+}
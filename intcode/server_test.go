@@ -0,0 +1,206 @@
+package intcode
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"io/ioutil"
+	"log"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Prints a prompt, echoes a line of input and prints 1000, which is not an
+// ASCII character, before halting.
+var echo = []int64{
+	104, 62, // 0: out '>'
+	3, 30, // 2: in [30]
+	1008, 30, 10, 31, // 4: [31] = [30] == '\n'
+	1005, 31, 16, // 8: jump to 16 if [31] != 0
+	4, 30, // 11: out [30]
+	1105, 1, 2, // 13: jump to 2
+	104, 1000, // 16: out 1000
+	99, // 18: halt
+}
+
+// Collects the log output of the server, which is written concurrently.
+type logBuffer struct {
+	mutex  sync.Mutex
+	buffer bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.String()
+}
+
+// Redirects the log output of the server to a buffer until restore is
+// called.
+func captureLog() (buffer *logBuffer, restore func()) {
+	buffer = new(logBuffer)
+	flags := log.Flags()
+	log.SetOutput(buffer)
+	log.SetFlags(0)
+	return buffer, func() {
+		log.SetOutput(os.Stderr)
+		log.SetFlags(flags)
+	}
+}
+
+// Serves on a loopback port until the returned listener is closed.
+func startServer(t *testing.T, server *Server) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(listener)
+	return listener
+}
+
+func dial(t *testing.T, listener net.Listener) (net.Conn, *bufio.Reader) {
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return conn, bufio.NewReader(conn)
+}
+
+func expectPrompt(t *testing.T, reader *bufio.Reader) {
+	t.Helper()
+	if b, err := reader.ReadByte(); err != nil || b != '>' {
+		t.Fatalf("got %q, %v, want the prompt", b, err)
+	}
+}
+
+func TestServeASCII(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- ServeASCII(MakeEmulator(echo), server)
+		server.Close()
+	}()
+
+	reader := bufio.NewReader(client)
+	expectPrompt(t, reader)
+
+	// The line ending is stripped and replaced by a single newline.
+	if _, err := io.WriteString(client, "hi\r\n"); err != nil {
+		t.Fatal(err)
+	}
+
+	rest, err := ioutil.ReadAll(reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(rest), "hi\n1000\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("ServeASCII: %v", err)
+	}
+}
+
+func TestServer(t *testing.T) {
+	_, restore := captureLog()
+	defer restore()
+
+	dir, err := ioutil.TempDir("", "intcode")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	listener := startServer(t, &Server{
+		Program:     echo,
+		MaxSessions: 1,
+		LogDir:      filepath.Join(dir, "sessions"),
+	})
+	defer listener.Close()
+
+	first, firstReader := dial(t, listener)
+	defer first.Close()
+	expectPrompt(t, firstReader)
+
+	// The first session is still running, so the second one is rejected.
+	second, secondReader := dial(t, listener)
+	defer second.Close()
+	rejection, err := ioutil.ReadAll(secondReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(rejection), "Too many sessions, try again later.\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	io.WriteString(first, "ok\n")
+	rest, err := ioutil.ReadAll(firstReader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(rest), "ok\n1000\n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// The log is written before the connection is closed, and it can be
+	// replayed.
+	session, err := LoadSession(filepath.Join(dir, "sessions", "session-0001.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := session.Inputs(), []int64{'o', 'k', '\n'}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got inputs %v, want %v", got, want)
+	}
+	if err := Replay(echo, session); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServerRecoversFromPanics(t *testing.T) {
+	logs, restore := captureLog()
+	defer restore()
+
+	listener := startServer(t, &Server{
+		Program: echo,
+		Handler: func(emulator *Emulator, client io.ReadWriter) error {
+			if _, err := io.WriteString(client, ">"); err != nil {
+				return err
+			}
+			line, err := ReadLine(bufio.NewReader(client))
+			if err != nil {
+				return err
+			}
+			panic("fault: " + line)
+		},
+	})
+	defer listener.Close()
+
+	for i := 0; i < 2; i++ {
+		conn, reader := dial(t, listener)
+		expectPrompt(t, reader)
+		io.WriteString(conn, "boom\n")
+
+		// The connection is closed, but the server keeps running.
+		if _, err := reader.ReadByte(); err != io.EOF {
+			t.Errorf("connection %d: got %v, want EOF", i+1, err)
+		}
+		conn.Close()
+	}
+
+	if !strings.Contains(logs.String(), "session 2: fault: boom") {
+		t.Errorf("the panic is not logged:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,204 @@
+package intcode
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+type EventKind int
+
+const (
+	EventInput  EventKind = 0
+	EventOutput EventKind = 1
+	EventHalt   EventKind = 2
+)
+
+var eventNames = map[EventKind]string{
+	EventInput:  "in",
+	EventOutput: "out",
+	EventHalt:   "halt",
+}
+
+// An event records a single value consumed or produced by the emulator. Step
+// is the number of instructions that were executed before the instruction
+// that caused the event.
+type Event struct {
+	Kind  EventKind
+	Step  int64
+	Value int64
+}
+
+func (e Event) String() string {
+	if e.Kind == EventHalt {
+		return fmt.Sprintf("%s %d", eventNames[e.Kind], e.Step)
+	}
+	return fmt.Sprintf("%s %d %d", eventNames[e.Kind], e.Step, e.Value)
+}
+
+// A session is the sequence of events of a single run of a program.
+//
+// Sessions are stored as text files with one event per line. Each line
+// contains the kind of the event ("in", "out" or "halt"), the step and the
+// value (except for "halt"). Empty lines and lines starting with "#" are
+// ignored.
+type Session struct {
+	Events []Event
+}
+
+// Inputs returns all values that were consumed by the program.
+func (s *Session) Inputs() []int64 {
+	return s.values(EventInput)
+}
+
+// Outputs returns all values that were produced by the program.
+func (s *Session) Outputs() []int64 {
+	return s.values(EventOutput)
+}
+
+func (s *Session) values(kind EventKind) (values []int64) {
+	for _, event := range s.Events {
+		if event.Kind == kind {
+			values = append(values, event.Value)
+		}
+	}
+	return
+}
+
+func (s *Session) WriteTo(w io.Writer) (int64, error) {
+	writer := bufio.NewWriter(w)
+	var total int64
+	for _, event := range s.Events {
+		n, err := fmt.Fprintln(writer, event)
+		total += int64(n)
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, writer.Flush()
+}
+
+func ReadSession(r io.Reader) (*Session, error) {
+	kinds := make(map[string]EventKind)
+	for kind, name := range eventNames {
+		kinds[name] = kind
+	}
+
+	session := new(Session)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		kind, ok := kinds[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown event %q", line, fields[0])
+		}
+
+		expected := 3
+		if kind == EventHalt {
+			expected = 2
+		}
+		if len(fields) != expected {
+			return nil, fmt.Errorf("line %d: expected %d fields, found %d", line, expected, len(fields))
+		}
+
+		var event Event
+		event.Kind = kind
+		for i, field := range fields[1:] {
+			value, err := strconv.ParseInt(field, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid number %q", line, field)
+			}
+			if i == 0 {
+				event.Step = value
+			} else {
+				event.Value = value
+			}
+		}
+
+		session.Events = append(session.Events, event)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
+
+func SaveSession(filename string, session *Session) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if _, err := session.WriteTo(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func LoadSession(filename string) (*Session, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	session, err := ReadSession(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", filename, err)
+	}
+	return session, nil
+}
+
+// Replay runs the program, providing the recorded input values whenever the
+// program requests input. It verifies that every event happens at the same
+// step and that the program produces the same output values as recorded. The
+// recording does not have to end with the program halting: the replay stops
+// successfully as soon as all recorded events have been verified.
+func Replay(program []int64, session *Session) error {
+	emulator := MakeEmulator(program)
+
+	replayed := new(Session)
+	emulator.Record(replayed)
+
+	for index := 0; index < len(session.Events); {
+		_, status := emulator.Emulate()
+
+		// Compare all events that happened since the last check. Events
+		// after the end of the recording are not checked.
+		for ; index < len(replayed.Events) && index < len(session.Events); index++ {
+			actual := replayed.Events[index]
+			if expected := session.Events[index]; actual != expected {
+				return fmt.Errorf("replay: event %d: expected %q, got %q", index, expected, actual)
+			}
+		}
+
+		switch status {
+		case EmulatorStatusHalted:
+			if index != len(session.Events) {
+				return fmt.Errorf("replay: event %d: program halted, but expected %q", index, session.Events[index])
+			}
+
+		case EmulatorStatusWaitingForInput:
+			if index == len(session.Events) {
+				break
+			}
+			expected := session.Events[index]
+			if expected.Kind != EventInput {
+				return fmt.Errorf("replay: event %d: expected %q, but program requested input at step %d", index, expected, emulator.steps)
+			}
+			emulator.Write(expected.Value)
+		}
+	}
+
+	return nil
+}
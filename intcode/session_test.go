@@ -0,0 +1,130 @@
+package intcode
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// Reads values and outputs each value doubled until it reads zero.
+var doubler = []int64{
+	3, 20, // 0: in [20]
+	1006, 20, 14, // 2: jump to 14 if [20] == 0
+	102, 2, 20, 21, // 5: [21] = 2 * [20]
+	4, 21, // 9: out [21]
+	1105, 1, 0, // 11: jump to 0
+	99, // 14: halt
+}
+
+func recordDoubler(t *testing.T, inputs ...int64) *Session {
+	t.Helper()
+
+	session := new(Session)
+	emulator := MakeEmulator(doubler, inputs...)
+	emulator.Record(session)
+	for {
+		if _, status := emulator.Emulate(); status != EmulatorStatusOutput {
+			if status != EmulatorStatusHalted {
+				t.Fatalf("unexpected status %d", status)
+			}
+			return session
+		}
+	}
+}
+
+func TestSessionRoundTrip(t *testing.T) {
+	session := recordDoubler(t, 3, 5, 0)
+
+	if got, want := session.Inputs(), []int64{3, 5, 0}; !reflect.DeepEqual(got, want) {
+		t.Errorf("inputs: got %v, want %v", got, want)
+	}
+	if got, want := session.Outputs(), []int64{6, 10}; !reflect.DeepEqual(got, want) {
+		t.Errorf("outputs: got %v, want %v", got, want)
+	}
+
+	var buffer bytes.Buffer
+	if _, err := session.WriteTo(&buffer); err != nil {
+		t.Fatal(err)
+	}
+
+	// Comments and empty lines are ignored.
+	read, err := ReadSession(strings.NewReader("# recorded by the test\n\n" + buffer.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, session) {
+		t.Errorf("got\n%v\nwant\n%v", read.Events, session.Events)
+	}
+
+	if err := Replay(doubler, read); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReplayPartial(t *testing.T) {
+	session := recordDoubler(t, 3, 5, 0)
+
+	// The recording may end after any event, e.g. after an input, after
+	// an output or while the program waits for input.
+	for length := 0; length <= len(session.Events); length++ {
+		partial := &Session{Events: session.Events[:length]}
+		if err := Replay(doubler, partial); err != nil {
+			t.Errorf("%d events: %v", length, err)
+		}
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(events []Event) []Event
+		err    string
+	}{
+		{"output", func(events []Event) []Event {
+			events[1].Value = 7
+			return events
+		}, `replay: event 1: expected "out 3 7", got "out 3 6"`},
+		{"step", func(events []Event) []Event {
+			events[1].Step++
+			return events
+		}, `replay: event 1: expected "out 4 6", got "out 3 6"`},
+		{"missing input", func(events []Event) []Event {
+			return append(events[:1:1], events[2:]...)
+		}, `replay: event 1: expected "in 5 5", got "out 3 6"`},
+		{"input expected", func(events []Event) []Event {
+			return append(events[:2:2], Event{EventOutput, 9, 1})
+		}, `replay: event 2: expected "out 9 1", but program requested input at step 5`},
+		{"halt", func(events []Event) []Event {
+			return append(events, Event{EventOutput, 20, 1})
+		}, `replay: event 6: program halted, but expected "out 20 1"`},
+	}
+
+	for _, test := range tests {
+		session := recordDoubler(t, 3, 5, 0)
+		session.Events = test.change(session.Events)
+
+		err := Replay(doubler, session)
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%s: got error %v, want %s", test.name, err, test.err)
+		}
+	}
+}
+
+func TestReadSessionErrors(t *testing.T) {
+	tests := []struct {
+		text, err string
+	}{
+		{"in 0 1\njump 4 2", `line 2: unknown event "jump"`},
+		{"out 3", "line 1: expected 3 fields, found 2"},
+		{"halt 3 0", "line 1: expected 2 fields, found 3"},
+		{"in 0 x", `line 1: invalid number "x"`},
+	}
+
+	for _, test := range tests {
+		_, err := ReadSession(strings.NewReader(test.text))
+		if err == nil || err.Error() != test.err {
+			t.Errorf("%q: got error %v, want %s", test.text, err, test.err)
+		}
+	}
+}
@@ -0,0 +1,217 @@
+// Package numtheory contains modular arithmetic on int64 that does not
+// overflow for moduli up to 2^63-1, the extended Euclidean algorithm, the
+// Chinese remainder theorem and overflow-checked least common multiples, with
+// math/big versions for results that do not fit into an int64.
+package numtheory
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/bits"
+)
+
+// ErrOverflow is returned if a result does not fit into an int64. The
+// functions ending in Big can be used instead.
+var ErrOverflow = errors.New("numtheory: overflow")
+
+// Mod returns a modulo m in the range [0, m), also for negative a. The
+// modulus must be positive.
+func Mod(a, m int64) int64 {
+	checkModulus(m)
+	a %= m
+	if a < 0 {
+		a += m
+	}
+	return a
+}
+
+// AddMod returns (a + b) mod m without overflowing.
+func AddMod(a, b, m int64) int64 {
+	a, b = Mod(a, m), Mod(b, m)
+	// Both are less than m <= 2^63-1, so the sum fits into a uint64.
+	return int64((uint64(a) + uint64(b)) % uint64(m))
+}
+
+// MulMod returns (a * b) mod m without overflowing, using the full 128-bit
+// product.
+func MulMod(a, b, m int64) int64 {
+	a, b = Mod(a, m), Mod(b, m)
+	hi, lo := bits.Mul64(uint64(a), uint64(b))
+	// hi < m, because a, b < m, so the division does not overflow.
+	_, rem := bits.Div64(hi, lo, uint64(m))
+	return int64(rem)
+}
+
+// PowMod returns base^exp mod m by binary exponentiation. The exponent must
+// not be negative.
+func PowMod(base, exp, m int64) int64 {
+	if exp < 0 {
+		panic(fmt.Sprintf("numtheory: negative exponent %d", exp))
+	}
+	result := Mod(1, m)
+	base = Mod(base, m)
+	for exp > 0 {
+		if exp&1 != 0 {
+			result = MulMod(result, base, m)
+		}
+		base = MulMod(base, base, m)
+		exp >>= 1
+	}
+	return result
+}
+
+// GCD returns the greatest common divisor of a and b, which is never
+// negative.
+func GCD(a, b int64) int64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	if a < 0 {
+		return -a
+	}
+	return a
+}
+
+// ExtendedGCD returns g = gcd(a, b) and x, y with a*x + b*y = g.
+func ExtendedGCD(a, b int64) (g, x, y int64) {
+	oldR, r := a, b
+	oldS, s := int64(1), int64(0)
+	oldT, t := int64(0), int64(1)
+	for r != 0 {
+		q := oldR / r
+		oldR, r = r, oldR-q*r
+		oldS, s = s, oldS-q*s
+		oldT, t = t, oldT-q*t
+	}
+	if oldR < 0 {
+		oldR, oldS, oldT = -oldR, -oldS, -oldT
+	}
+	return oldR, oldS, oldT
+}
+
+// InverseMod returns x with a*x = 1 (mod m), or an error if a and m are not
+// coprime.
+func InverseMod(a, m int64) (int64, error) {
+	g, x, _ := ExtendedGCD(Mod(a, m), m)
+	if g != 1 {
+		return 0, fmt.Errorf("numtheory: %d has no inverse modulo %d", a, m)
+	}
+	return Mod(x, m), nil
+}
+
+// LCM returns the least common multiple of the values, or ErrOverflow if it
+// does not fit into an int64. The result is never negative and is 0 if any
+// value is 0.
+func LCM(values ...int64) (int64, error) {
+	result := int64(1)
+	for _, value := range values {
+		if value == 0 {
+			return 0, nil
+		}
+		if value < 0 {
+			if value == math.MinInt64 {
+				return 0, ErrOverflow
+			}
+			value = -value
+		}
+
+		factor := value / GCD(result, value)
+		if result > math.MaxInt64/factor {
+			return 0, ErrOverflow
+		}
+		result *= factor
+	}
+	return result, nil
+}
+
+// LCMBig is like LCM, but cannot overflow.
+func LCMBig(values ...int64) *big.Int {
+	result := big.NewInt(1)
+	var g, v big.Int
+	for _, value := range values {
+		if value == 0 {
+			return big.NewInt(0)
+		}
+		v.SetInt64(value)
+		v.Abs(&v)
+		g.GCD(nil, nil, result, &v)
+		result.Mul(result, v.Quo(&v, &g))
+	}
+	return result
+}
+
+// CRT solves the system x = remainders[i] (mod moduli[i]) and returns the
+// smallest non-negative solution x and the modulus m of all solutions (the
+// least common multiple of the moduli). The moduli need not be coprime, but
+// then the system may have no solution, which is reported as an error. If m
+// does not fit into an int64, the error is ErrOverflow.
+func CRT(remainders, moduli []int64) (x, m int64, err error) {
+	if len(remainders) != len(moduli) {
+		panic("numtheory: CRT needs as many remainders as moduli")
+	}
+
+	x, m = 0, 1
+	for i, modulus := range moduli {
+		checkModulus(modulus)
+		r := Mod(remainders[i], modulus)
+
+		// Find t with x + m*t = r (mod modulus).
+		g, p, _ := ExtendedGCD(m, modulus)
+		diff := r - Mod(x, modulus)
+		if diff%g != 0 {
+			return 0, 0, fmt.Errorf("numtheory: no solution for x = %d (mod %d) and x = %d (mod %d)", x, m, remainders[i], modulus)
+		}
+
+		step := modulus / g
+		if m > math.MaxInt64/step {
+			return 0, 0, ErrOverflow
+		}
+		t := MulMod(diff/g, p, step)
+
+		// x < m and t < step, so this is less than m*step and does not
+		// overflow.
+		x += m * t
+		m *= step
+	}
+	return x, m, nil
+}
+
+// CRTBig is like CRT, but the result may be arbitrarily large.
+func CRTBig(remainders, moduli []int64) (x, m *big.Int, err error) {
+	if len(remainders) != len(moduli) {
+		panic("numtheory: CRT needs as many remainders as moduli")
+	}
+
+	x, m = big.NewInt(0), big.NewInt(1)
+	var g, p, r, modulus, diff, t big.Int
+	for i := range moduli {
+		checkModulus(moduli[i])
+		modulus.SetInt64(moduli[i])
+		r.SetInt64(remainders[i])
+		r.Mod(&r, &modulus)
+
+		g.GCD(&p, nil, m, &modulus)
+		diff.Sub(&r, diff.Mod(x, &modulus))
+		if t.Rem(&diff, &g).Sign() != 0 {
+			return nil, nil, fmt.Errorf("numtheory: no solution for x = %v (mod %v) and x = %d (mod %d)", x, m, remainders[i], moduli[i])
+		}
+
+		step := new(big.Int).Quo(&modulus, &g)
+		t.Quo(&diff, &g)
+		t.Mul(&t, &p)
+		t.Mod(&t, step)
+
+		x.Add(x, t.Mul(m, &t))
+		m.Mul(m, step)
+		x.Mod(x, m)
+	}
+	return x, m, nil
+}
+
+func checkModulus(m int64) {
+	if m <= 0 {
+		panic(fmt.Sprintf("numtheory: invalid modulus %d", m))
+	}
+}
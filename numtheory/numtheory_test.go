@@ -0,0 +1,131 @@
+package numtheory
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestMulMod(t *testing.T) {
+	const m = 119315717514047
+	tests := [][2]int64{
+		{m - 1, m - 1}, {123456789012345, 98765432109876}, {-5, 7}, {0, m - 1},
+		{math.MaxInt64, math.MaxInt64},
+	}
+	for _, test := range tests {
+		a, b := test[0], test[1]
+		want := new(big.Int).Mul(big.NewInt(a), big.NewInt(b))
+		want.Mod(want, big.NewInt(m))
+		if got := MulMod(a, b, m); got != want.Int64() {
+			t.Errorf("%d * %d mod %d: got %d, want %v", a, b, int64(m), got, want)
+		}
+	}
+
+	if got := MulMod(math.MaxInt64-1, 2, math.MaxInt64); got != math.MaxInt64-2 {
+		t.Errorf("got %d with the largest modulus", got)
+	}
+}
+
+func TestPowMod(t *testing.T) {
+	if got := PowMod(2, 10, 1000); got != 24 {
+		t.Errorf("got %d, want 24", got)
+	}
+	if got := PowMod(5, 0, 1); got != 0 {
+		t.Errorf("got %d modulo 1, want 0", got)
+	}
+
+	// Fermat's little theorem.
+	const p = 119315717514047
+	if got := PowMod(123456789, p-1, p); got != 1 {
+		t.Errorf("got %d, want 1", got)
+	}
+}
+
+func TestExtendedGCD(t *testing.T) {
+	tests := [][2]int64{{240, 46}, {46, 240}, {-12, 18}, {17, 0}, {0, 5}}
+	for _, test := range tests {
+		a, b := test[0], test[1]
+		g, x, y := ExtendedGCD(a, b)
+		if g != GCD(a, b) || a*x+b*y != g {
+			t.Errorf("%d, %d: got g=%d x=%d y=%d", a, b, g, x, y)
+		}
+	}
+}
+
+func TestInverseMod(t *testing.T) {
+	inverse, err := InverseMod(3, 10007)
+	if err != nil || MulMod(3, inverse, 10007) != 1 {
+		t.Errorf("got %d, %v", inverse, err)
+	}
+	if _, err := InverseMod(4, 10); err == nil {
+		t.Errorf("expected an error for 4 modulo 10")
+	}
+}
+
+func TestLCM(t *testing.T) {
+	if got, err := LCM(2028, 5898, 4702); err != nil || got != 4686774924 {
+		t.Errorf("got %d, %v", got, err)
+	}
+	if got, err := LCM(-4, 6); err != nil || got != 12 {
+		t.Errorf("got %d, %v", got, err)
+	}
+	if _, err := LCM(math.MaxInt64, math.MaxInt64-1); err != ErrOverflow {
+		t.Errorf("got error %v, want ErrOverflow", err)
+	}
+
+	// Consecutive numbers are coprime.
+	want := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(math.MaxInt64-1))
+	if got := LCMBig(math.MaxInt64, math.MaxInt64-1); got.Cmp(want) != 0 {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestCRT(t *testing.T) {
+	tests := []struct {
+		remainders, moduli []int64
+		x, m               int64
+	}{
+		{[]int64{2, 3, 2}, []int64{3, 5, 7}, 23, 105},
+		{[]int64{0, -2, -3}, []int64{17, 13, 19}, 3417, 4199},
+		{[]int64{3, 5}, []int64{4, 6}, 11, 12}, // not coprime
+	}
+	for _, test := range tests {
+		x, m, err := CRT(test.remainders, test.moduli)
+		if err != nil {
+			t.Errorf("%v mod %v: %v", test.remainders, test.moduli, err)
+			continue
+		}
+		for i := range test.moduli {
+			if Mod(x, test.moduli[i]) != Mod(test.remainders[i], test.moduli[i]) {
+				t.Errorf("%v mod %v: %d is not a solution", test.remainders, test.moduli, x)
+			}
+		}
+		if x != test.x || m != test.m {
+			t.Errorf("%v mod %v: got %d mod %d, want %d mod %d", test.remainders, test.moduli, x, m, test.x, test.m)
+		}
+
+		bx, bm, err := CRTBig(test.remainders, test.moduli)
+		if err != nil || bx.Int64() != x || bm.Int64() != m {
+			t.Errorf("%v mod %v: big version got %v mod %v, %v", test.remainders, test.moduli, bx, bm, err)
+		}
+	}
+
+	if _, _, err := CRT([]int64{1, 2}, []int64{4, 6}); err == nil {
+		t.Errorf("expected an error for an unsolvable system")
+	}
+
+	moduli := []int64{math.MaxInt64, math.MaxInt64 - 1}
+	if _, _, err := CRT([]int64{1, 2}, moduli); err != ErrOverflow {
+		t.Errorf("got error %v, want ErrOverflow", err)
+	}
+	x, m, err := CRTBig([]int64{1, 2}, moduli)
+	if err != nil || m.Cmp(LCMBig(moduli...)) != 0 {
+		t.Fatalf("got %v mod %v, %v", x, m, err)
+	}
+	for i, modulus := range moduli {
+		r := new(big.Int).Mod(x, big.NewInt(modulus))
+		if r.Int64() != int64(i+1) {
+			t.Errorf("big solution %v has remainder %v modulo %d", x, r, modulus)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Package ocr recognizes the block letters that some puzzles draw as their
+// answer (e.g. days 8 and 11).
+package ocr
+
+import (
+	"fmt"
+	"image"
+	"strings"
+
+	"greenlightning.eu/aoc19/render"
+)
+
+// The letters are 6 pixels high and usually 4 pixels wide, with at least one
+// empty column between them. The keys are the rows of each letter, with
+// empty columns at the sides removed.
+var font = map[string]rune{
+	".##.\n#..#\n#..#\n####\n#..#\n#..#":       'A',
+	"###.\n#..#\n###.\n#..#\n#..#\n###.":       'B',
+	".##.\n#..#\n#...\n#...\n#..#\n.##.":       'C',
+	"####\n#...\n###.\n#...\n#...\n####":       'E',
+	"####\n#...\n###.\n#...\n#...\n#...":       'F',
+	".##.\n#..#\n#...\n#.##\n#..#\n.###":       'G',
+	"#..#\n#..#\n####\n#..#\n#..#\n#..#":       'H',
+	"###\n.#.\n.#.\n.#.\n.#.\n###":             'I',
+	"..##\n...#\n...#\n...#\n#..#\n.##.":       'J',
+	"#..#\n#.#.\n##..\n#.#.\n#.#.\n#..#":       'K',
+	"#...\n#...\n#...\n#...\n#...\n####":       'L',
+	".##.\n#..#\n#..#\n#..#\n#..#\n.##.":       'O',
+	"###.\n#..#\n#..#\n###.\n#...\n#...":       'P',
+	"###.\n#..#\n#..#\n###.\n#.#.\n#..#":       'R',
+	".###\n#...\n#...\n.##.\n...#\n###.":       'S',
+	"#..#\n#..#\n#..#\n#..#\n#..#\n.##.":       'U',
+	"#...#\n#...#\n.#.#.\n..#..\n..#..\n..#..": 'Y',
+	"####\n...#\n..#.\n.#..\n#...\n####":       'Z',
+}
+
+const height = 6
+
+// Read recognizes the letters in the grid. Positions with the value 1 are
+// lit, everything else is background. The lit positions must form a single
+// line of letters, but the grid may have any margin around them.
+func Read(grid render.Grid) (string, error) {
+	lit := func(x, y int) bool {
+		value, ok := grid.At(image.Point{x, y})
+		return ok && value == 1
+	}
+
+	// Find the smallest rectangle that contains all lit positions.
+	var bounds image.Rectangle
+	all := grid.Bounds()
+	for y := all.Min.Y; y < all.Max.Y; y++ {
+		for x := all.Min.X; x < all.Max.X; x++ {
+			if lit(x, y) {
+				bounds = bounds.Union(image.Rect(x, y, x+1, y+1))
+			}
+		}
+	}
+
+	if bounds.Empty() {
+		return "", fmt.Errorf("ocr: no letters found")
+	}
+	if bounds.Dy() != height {
+		return "", fmt.Errorf("ocr: letters must be %d pixels high, got %d", height, bounds.Dy())
+	}
+
+	emptyColumn := func(x int) bool {
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			if lit(x, y) {
+				return false
+			}
+		}
+		return true
+	}
+
+	var result strings.Builder
+	for x := bounds.Min.X; x < bounds.Max.X; {
+		if emptyColumn(x) {
+			x++
+			continue
+		}
+
+		start := x
+		for x < bounds.Max.X && !emptyColumn(x) {
+			x++
+		}
+
+		var rows []string
+		for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+			var row strings.Builder
+			for column := start; column < x; column++ {
+				if lit(column, y) {
+					row.WriteByte('#')
+				} else {
+					row.WriteByte('.')
+				}
+			}
+			rows = append(rows, row.String())
+		}
+
+		glyph := strings.Join(rows, "\n")
+		letter, ok := font[glyph]
+		if !ok {
+			return "", fmt.Errorf("ocr: unknown letter at column %d:\n%s", start-all.Min.X, glyph)
+		}
+		result.WriteRune(letter)
+	}
+
+	return result.String(), nil
+}
+
+// ReadBytes recognizes the letters in an image that is stored in row-major
+// order with one byte per pixel (see Read).
+func ReadBytes(pixels []byte, width int) (string, error) {
+	if width <= 0 {
+		return "", fmt.Errorf("ocr: invalid width %d", width)
+	}
+	if len(pixels)%width != 0 {
+		return "", fmt.Errorf("ocr: %d pixels do not form rows of %d pixels", len(pixels), width)
+	}
+
+	grid := render.MakeDense(width, len(pixels)/width)
+	for i, pixel := range pixels {
+		grid.Values[i] = int(pixel)
+	}
+	return Read(grid)
+}
@@ -0,0 +1,137 @@
+package ocr
+
+import (
+	"image"
+	"strings"
+	"testing"
+
+	"greenlightning.eu/aoc19/render"
+)
+
+// The image decoded by day 8.
+const hgbcf = `
+#..#..##..###...##..####.
+#..#.#..#.#..#.#..#.#....
+####.#....###..#....###..
+#..#.#.##.#..#.#....#....
+#..#.#..#.#..#.#..#.#....
+#..#..###.###...##..#....`
+
+// The hull painted by day 11, which has a margin and a wider gap before the
+// last letter.
+const apugurfh = `
+..##..###..#..#..##..#..#.###..####.#..#...
+.#..#.#..#.#..#.#..#.#..#.#..#.#....#..#...
+.#..#.#..#.#..#.#....#..#.#..#.###..####...
+.####.###..#..#.#.##.#..#.###..#....#..#...
+.#..#.#....#..#.#..#.#..#.#.#..#....#..#...
+.#..#.#.....##...###..##..#..#.#....#..#...`
+
+// Converts a banner to a grid whose top left corner is at offset. Lit
+// pixels have the value 1 and all others 0, like the days use them.
+func parse(banner string, offset image.Point) render.Sparse {
+	grid := make(render.Sparse)
+	for y, line := range strings.Split(strings.TrimPrefix(banner, "\n"), "\n") {
+		for x, char := range line {
+			value := 0
+			if char == '#' {
+				value = 1
+			}
+			grid[image.Point{x, y}.Add(offset)] = value
+		}
+	}
+	return grid
+}
+
+func TestRead(t *testing.T) {
+	tests := []struct {
+		name    string
+		banner  string
+		offset  image.Point
+		letters string
+	}{
+		{"day 8", hgbcf, image.Point{}, "HGBCF"},
+		{"day 11", apugurfh, image.Point{-3, 7}, "APUGURFH"},
+		// I and Y are not 4 pixels wide.
+		{"narrow and wide", `
+###..#...#..##.
+.#...#...#.#..#
+.#....#.#..#..#
+.#.....#...####
+.#.....#...#..#
+###....#...#..#`, image.Point{}, "IYA"},
+	}
+
+	for _, test := range tests {
+		letters, err := Read(parse(test.banner, test.offset))
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if letters != test.letters {
+			t.Errorf("%s: got %q, want %q", test.name, letters, test.letters)
+		}
+	}
+}
+
+func TestReadBytes(t *testing.T) {
+	var pixels []byte
+	for _, char := range strings.Replace(hgbcf, "\n", "", -1) {
+		if char == '#' {
+			pixels = append(pixels, 1)
+		} else {
+			pixels = append(pixels, 0)
+		}
+	}
+
+	letters, err := ReadBytes(pixels, 25)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if letters != "HGBCF" {
+		t.Errorf("got %q, want HGBCF", letters)
+	}
+
+	for _, width := range []int{0, -25, 24} {
+		if _, err := ReadBytes(pixels, width); err == nil {
+			t.Errorf("width %d: expected an error", width)
+		}
+	}
+}
+
+func TestReadErrors(t *testing.T) {
+	tests := []struct {
+		name, banner, err string
+	}{
+		{"empty", `
+....
+....`, "ocr: no letters found"},
+		{"too low", `
+#..#
+####
+#..#`, "ocr: letters must be 6 pixels high, got 3"},
+		// The top right pixel of the H is missing.
+		{"unknown letter", `
+.##...#...
+#..#..#..#
+#.....####
+#.##..#..#
+#..#..#..#
+.###..#..#`, "ocr: unknown letter at column 6:\n#...\n#..#\n####\n#..#\n#..#\n#..#"},
+		// Without an empty column, two letters are read as one.
+		{"no spacing", `
+#..##..#
+#..##..#
+########
+#..##..#
+#..##..#
+#..##..#`, "ocr: unknown letter at column 0:"},
+	}
+
+	for _, test := range tests {
+		_, err := Read(parse(test.banner, image.Point{}))
+		if err == nil || !strings.HasPrefix(err.Error(), test.err) {
+			t.Errorf("%s: got error %v, want %q", test.name, err, test.err)
+		}
+	}
+}
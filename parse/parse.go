@@ -0,0 +1,165 @@
+// Package parse contains helpers for parsing puzzle inputs line by line. A
+// Pattern matches a whole line and converts its submatches to typed values.
+// The errors carry the file name, line number and text of the offending line,
+// so that a malformed input gives a readable message instead of an index out
+// of range panic.
+package parse
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// ErrNoMatch is returned (wrapped) by Scan if the text does not match the
+// pattern.
+var ErrNoMatch = errors.New("no match")
+
+// An Error describes a line of the input that could not be parsed.
+type Error struct {
+	File string
+	Line int // starting at 1
+	Text string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s:%d: %v: %q", e.File, e.Line, e.Err, e.Text)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Lines reads the file and calls fn for each line. If fn returns an error,
+// it is returned as an *Error for that line.
+func Lines(filename string, fn func(line string) error) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	number := 0
+	for scanner.Scan() {
+		number++
+		if err := fn(scanner.Text()); err != nil {
+			return &Error{File: filename, Line: number, Text: scanner.Text(), Err: err}
+		}
+	}
+	return scanner.Err()
+}
+
+// Each calls fn for each line, like Lines, for lines that have already been
+// read. The name is used in the errors.
+func Each(name string, lines []string, fn func(line string) error) error {
+	for index, line := range lines {
+		if err := fn(line); err != nil {
+			return &Error{File: name, Line: index + 1, Text: line, Err: err}
+		}
+	}
+	return nil
+}
+
+// A Pattern is a regular expression that must match the whole text.
+type Pattern struct {
+	expr  string
+	regex *regexp.Regexp
+}
+
+// Compile parses the expression. It does not need to be anchored with ^ and
+// $, because a pattern always matches the whole text.
+func Compile(expr string) (*Pattern, error) {
+	regex, err := regexp.Compile(`^(?:` + expr + `)$`)
+	if err != nil {
+		return nil, err
+	}
+	return &Pattern{expr: expr, regex: regex}, nil
+}
+
+// MustCompile is like Compile, but panics if the expression is invalid. It
+// is meant for patterns in global variables.
+func MustCompile(expr string) *Pattern {
+	p, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func (p *Pattern) String() string {
+	return p.expr
+}
+
+// MatchString reports whether the pattern matches the text, for choosing
+// between alternatives before scanning.
+func (p *Pattern) MatchString(text string) bool {
+	return p.regex.MatchString(text)
+}
+
+// Scan matches the text and stores the submatches in dest, which must
+// contain one pointer per group of the pattern. Supported pointer types are
+// *string, *int and *int64.
+func (p *Pattern) Scan(text string, dest ...interface{}) error {
+	if len(dest) != p.regex.NumSubexp() {
+		panic(fmt.Sprintf("parse: pattern %s has %d groups, but Scan got %d values", p.expr, p.regex.NumSubexp(), len(dest)))
+	}
+
+	match := p.regex.FindStringSubmatch(text)
+	if match == nil {
+		return fmt.Errorf("%w for %s", ErrNoMatch, p.expr)
+	}
+
+	for i, d := range dest {
+		if err := store(match[i+1], d); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func store(text string, dest interface{}) error {
+	switch dest := dest.(type) {
+	case *string:
+		*dest = text
+
+	case *int:
+		value, err := Int(text)
+		if err != nil {
+			return err
+		}
+		*dest = value
+
+	case *int64:
+		value, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return numberError(text, err)
+		}
+		*dest = value
+
+	default:
+		panic(fmt.Sprintf("parse: unsupported type %T", dest))
+	}
+	return nil
+}
+
+// Int converts a decimal number and returns a short error without the
+// details of strconv.
+func Int(text string) (int, error) {
+	value, err := strconv.Atoi(text)
+	if err != nil {
+		return 0, numberError(text, err)
+	}
+	return value, nil
+}
+
+func numberError(text string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("number %q out of range", text)
+	}
+	return fmt.Errorf("invalid number %q", text)
+}
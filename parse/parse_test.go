@@ -0,0 +1,85 @@
+package parse
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+var pointPattern = MustCompile(`(\w+) at (-?\d+),(-?\d+)`)
+
+func TestScan(t *testing.T) {
+	var name string
+	var x int
+	var y int64
+	if err := pointPattern.Scan("a at -3,12", &name, &x, &y); err != nil {
+		t.Fatal(err)
+	}
+	if name != "a" || x != -3 || y != 12 {
+		t.Errorf("got %s %d %d", name, x, y)
+	}
+
+	// The pattern must match the whole text.
+	if err := pointPattern.Scan("a at 1,2 and more", &name, &x, &y); !errors.Is(err, ErrNoMatch) {
+		t.Errorf("got error %v, want ErrNoMatch", err)
+	}
+
+	if err := pointPattern.Scan("a at 1,99999999999999999999", &name, &x, &y); err == nil {
+		t.Errorf("expected an error for a number that is out of range")
+	}
+}
+
+func TestEach(t *testing.T) {
+	lines := []string{"a at 1,2", "b at 3;4"}
+
+	var names []string
+	err := Each("example", lines, func(line string) error {
+		var name string
+		var x, y int
+		if err := pointPattern.Scan(line, &name, &x, &y); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+
+	var parseErr *Error
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("got error %v, want *Error", err)
+	}
+	if parseErr.File != "example" || parseErr.Line != 2 || parseErr.Text != "b at 3;4" {
+		t.Errorf("got %+v", parseErr)
+	}
+	if !errors.Is(err, ErrNoMatch) {
+		t.Errorf("error %v does not wrap ErrNoMatch", err)
+	}
+	if len(names) != 1 {
+		t.Errorf("got %v, want only the first line", names)
+	}
+}
+
+func TestLines(t *testing.T) {
+	file, err := ioutil.TempFile("", "parse-*.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(file.Name())
+	file.WriteString("1\n2\nthree\n")
+	file.Close()
+
+	sum := 0
+	err = Lines(file.Name(), func(line string) error {
+		value, err := Int(line)
+		sum += value
+		return err
+	})
+
+	want := file.Name() + `:3: invalid number "three": "three"`
+	if err == nil || err.Error() != want {
+		t.Errorf("got error %v, want %s", err, want)
+	}
+	if sum != 3 {
+		t.Errorf("got sum %d, want 3", sum)
+	}
+}
@@ -0,0 +1,160 @@
+// Package priority implements an indexed priority queue that does not need to
+// be adapted to each puzzle: the items carry arbitrary values, the order is
+// given by a comparison function and every pushed item is a handle that can
+// later be used to change its priority or to remove it from the queue.
+package priority
+
+import "container/heap"
+
+// An Item is a value in a queue. The Priority must only be changed with
+// Queue.Update, or followed by a call to Queue.Fix, so that the queue can
+// restore its order.
+type Item struct {
+	Value    interface{}
+	Priority int
+
+	queue *Queue
+	index int
+}
+
+// Less reports whether a must be popped before b.
+type Less func(a, b *Item) bool
+
+// MinFirst pops the item with the lowest priority first, which is the usual
+// order for shortest path searches.
+func MinFirst(a, b *Item) bool {
+	return a.Priority < b.Priority
+}
+
+// MaxFirst pops the item with the highest priority first.
+func MaxFirst(a, b *Item) bool {
+	return a.Priority > b.Priority
+}
+
+type Queue struct {
+	storage storage
+}
+
+// MakeQueue returns a queue with the given order (MinFirst if less is nil)
+// that contains the items, which are arranged in linear time instead of
+// being pushed one by one.
+func MakeQueue(less Less, items ...*Item) *Queue {
+	if less == nil {
+		less = MinFirst
+	}
+
+	q := &Queue{storage: storage{less: less}}
+	for _, item := range items {
+		if item.queue != nil {
+			panic("priority: item is already in a queue")
+		}
+		item.queue, item.index = q, len(q.storage.items)
+		q.storage.items = append(q.storage.items, item)
+	}
+	heap.Init(&q.storage)
+	return q
+}
+
+func (q *Queue) Len() int {
+	return len(q.storage.items)
+}
+
+func (q *Queue) Empty() bool {
+	return len(q.storage.items) == 0
+}
+
+// Push adds a value to the queue and returns its item.
+func (q *Queue) Push(value interface{}, priority int) *Item {
+	item := &Item{Value: value, Priority: priority}
+	q.PushItem(item)
+	return item
+}
+
+// PushItem adds an item that is not in a queue, e.g. one that has been
+// popped or removed before.
+func (q *Queue) PushItem(item *Item) {
+	if item.queue != nil {
+		panic("priority: item is already in a queue")
+	}
+	item.queue = q
+	heap.Push(&q.storage, item)
+}
+
+// Peek returns the next item without removing it, or nil if the queue is
+// empty.
+func (q *Queue) Peek() *Item {
+	if q.Empty() {
+		return nil
+	}
+	return q.storage.items[0]
+}
+
+// Pop removes and returns the next item. The queue must not be empty.
+func (q *Queue) Pop() *Item {
+	return heap.Pop(&q.storage).(*Item)
+}
+
+// Contains reports whether the item is in this queue.
+func (q *Queue) Contains(item *Item) bool {
+	return item.queue == q
+}
+
+// Update changes the priority of the item, which can be an increase or a
+// decrease.
+func (q *Queue) Update(item *Item, priority int) {
+	item.Priority = priority
+	q.Fix(item)
+}
+
+// Fix restores the order after the item has been changed in a way that
+// affects the comparison function.
+func (q *Queue) Fix(item *Item) {
+	q.check(item)
+	heap.Fix(&q.storage, item.index)
+}
+
+// Remove removes the item from the queue.
+func (q *Queue) Remove(item *Item) {
+	q.check(item)
+	heap.Remove(&q.storage, item.index)
+}
+
+func (q *Queue) check(item *Item) {
+	if item.queue != q {
+		panic("priority: item is not in this queue")
+	}
+}
+
+// The implementation of heap.Interface.
+type storage struct {
+	items []*Item
+	less  Less
+}
+
+func (s storage) Len() int {
+	return len(s.items)
+}
+
+func (s storage) Less(i, j int) bool {
+	return s.less(s.items[i], s.items[j])
+}
+
+func (s storage) Swap(i, j int) {
+	s.items[i], s.items[j] = s.items[j], s.items[i]
+	s.items[i].index, s.items[j].index = i, j
+}
+
+func (s *storage) Push(x interface{}) {
+	item := x.(*Item)
+	item.index = len(s.items)
+	s.items = append(s.items, item)
+}
+
+func (s *storage) Pop() interface{} {
+	n := len(s.items)
+	item := s.items[n-1]
+	s.items[n-1] = nil
+	s.items = s.items[:n-1]
+	item.queue, item.index = nil, -1
+	return item
+}
@@ -0,0 +1,99 @@
+package priority
+
+import (
+	"reflect"
+	"testing"
+)
+
+func popAll(q *Queue) []int {
+	var result []int
+	for !q.Empty() {
+		result = append(result, q.Pop().Priority)
+	}
+	return result
+}
+
+func TestOrder(t *testing.T) {
+	priorities := []int{1, 6, 100, 9, 3, 4, 2, 8, 7}
+
+	q := MakeQueue(nil)
+	for _, p := range priorities {
+		q.Push(nil, p)
+	}
+	if got, want := popAll(q), []int{1, 2, 3, 4, 6, 7, 8, 9, 100}; !reflect.DeepEqual(got, want) {
+		t.Errorf("min first: got %v, want %v", got, want)
+	}
+
+	var items []*Item
+	for _, p := range priorities {
+		items = append(items, &Item{Priority: p})
+	}
+	q = MakeQueue(MaxFirst, items...)
+	if got, want := popAll(q), []int{100, 9, 8, 7, 6, 4, 3, 2, 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("max first: got %v, want %v", got, want)
+	}
+}
+
+func TestCustomOrder(t *testing.T) {
+	// Equal priorities are ordered by name.
+	less := func(a, b *Item) bool {
+		if a.Priority != b.Priority {
+			return a.Priority < b.Priority
+		}
+		return a.Value.(string) < b.Value.(string)
+	}
+
+	q := MakeQueue(less)
+	for _, name := range []string{"d", "b", "a", "c"} {
+		q.Push(name, 1)
+	}
+	q.Push("z", 0)
+
+	var got []string
+	for !q.Empty() {
+		got = append(got, q.Pop().Value.(string))
+	}
+	if want := []string{"z", "a", "b", "c", "d"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestHandles(t *testing.T) {
+	q := MakeQueue(MinFirst)
+	a := q.Push("a", 5)
+	b := q.Push("b", 10)
+	c := q.Push("c", 15)
+
+	// Decrease and increase.
+	q.Update(c, 1)
+	q.Update(a, 20)
+	if got := q.Peek(); got != c {
+		t.Errorf("got %v after update, want c", got.Value)
+	}
+
+	q.Remove(b)
+	if q.Contains(b) || !q.Contains(a) {
+		t.Errorf("Contains is wrong after Remove")
+	}
+	if q.Len() != 2 {
+		t.Errorf("got length %d, want 2", q.Len())
+	}
+
+	if got := q.Pop(); got != c || q.Contains(c) {
+		t.Errorf("got %v, want c", got.Value)
+	}
+
+	// A popped item can be pushed again.
+	q.PushItem(c)
+	if got := q.Pop(); got != c {
+		t.Errorf("got %v, want c", got.Value)
+	}
+
+	other := MakeQueue(nil)
+	if other.Contains(a) {
+		t.Errorf("item is in the wrong queue")
+	}
+	if q.Pop() != a || !q.Empty() || q.Peek() != nil {
+		t.Errorf("queue is not empty")
+	}
+}
@@ -0,0 +1,42 @@
+package render
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteFile renders the grid to a file. The format is chosen based on the
+// extension of the filename: ".png" for PNG, ".svg" for SVG, ".ans" for ANSI
+// colored text and plain text for everything else. If the filename is "-",
+// the grid is written to standard output as ANSI colored text.
+func WriteFile(filename string, grid Grid, style Style) error {
+	if filename == "-" {
+		return ANSI(os.Stdout, grid, style)
+	}
+
+	var write func(io.Writer, Grid, Style) error
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".png":
+		write = PNG
+	case ".svg":
+		write = SVG
+	case ".ans":
+		write = ANSI
+	default:
+		write = Text
+	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := write(file, grid, style); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
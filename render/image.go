@@ -0,0 +1,37 @@
+package render
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"io"
+)
+
+// Image draws each tile as a square of style.Scale pixels.
+func Image(grid Grid, style Style) *image.RGBA {
+	bounds := grid.Bounds()
+	scale := style.scale()
+
+	result := image.NewRGBA(image.Rect(0, 0, bounds.Dx()*scale, bounds.Dy()*scale))
+	draw.Draw(result, result.Bounds(), image.NewUniform(style.background()), image.Point{}, draw.Src)
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			tile := style.tile(grid, image.Point{x, y})
+			if tile.Color == nil {
+				continue
+			}
+
+			min := image.Point{x - bounds.Min.X, y - bounds.Min.Y}.Mul(scale)
+			rect := image.Rectangle{min, min.Add(image.Point{scale, scale})}
+			draw.Draw(result, rect, image.NewUniform(tile.Color), image.Point{}, draw.Over)
+		}
+	}
+
+	return result
+}
+
+// PNG writes the image of the grid (see Image) in PNG format.
+func PNG(w io.Writer, grid Grid, style Style) error {
+	return png.Encode(w, Image(grid, style))
+}
@@ -0,0 +1,173 @@
+// Package render draws two-dimensional grids of tiles as plain text, ANSI
+// colored text, PNG images and SVG images.
+package render
+
+import (
+	"image"
+	"image/color"
+
+	"greenlightning.eu/aoc19/grid"
+)
+
+// A Grid provides the value of each position within its bounds. If ok is
+// false, the value at that position is unknown.
+type Grid interface {
+	Bounds() image.Rectangle
+	At(p image.Point) (value int, ok bool)
+}
+
+// Sparse is a grid that only stores the known positions. Its bounds are the
+// smallest rectangle that contains all of them.
+type Sparse map[image.Point]int
+
+// Bounds uses grid.Extend, so that a sparse map has the same bounds as a
+// grid.Sparse with the same positions.
+func (sparse Sparse) Bounds() image.Rectangle {
+	var bounds image.Rectangle
+	for p := range sparse {
+		bounds = grid.Extend(bounds, p)
+	}
+	return bounds
+}
+
+func (sparse Sparse) At(p image.Point) (int, bool) {
+	value, ok := sparse[p]
+	return value, ok
+}
+
+// Dense is a grid that stores the values of all positions in row-major order.
+type Dense struct {
+	Width, Height int
+	Values        []int
+}
+
+func MakeDense(width, height int) Dense {
+	return Dense{width, height, make([]int, width*height)}
+}
+
+func (grid Dense) Bounds() image.Rectangle {
+	return image.Rect(0, 0, grid.Width, grid.Height)
+}
+
+func (grid Dense) At(p image.Point) (int, bool) {
+	if !p.In(grid.Bounds()) {
+		return 0, false
+	}
+	return grid.Values[p.Y*grid.Width+p.X], true
+}
+
+func (grid Dense) Set(p image.Point, value int) {
+	grid.Values[p.Y*grid.Width+p.X] = value
+}
+
+// Lines returns a dense grid where the value of each position is the
+// character at that position. Shorter lines are padded with spaces.
+func Lines(lines []string) Dense {
+	width := 0
+	for _, line := range lines {
+		width = max(width, len([]rune(line)))
+	}
+
+	grid := MakeDense(width, len(lines))
+	for y, line := range lines {
+		runes := []rune(line)
+		for x := 0; x < width; x++ {
+			if x < len(runes) {
+				grid.Set(image.Point{x, y}, int(runes[x]))
+			} else {
+				grid.Set(image.Point{x, y}, ' ')
+			}
+		}
+	}
+	return grid
+}
+
+// Func returns a grid that calls at to get the value of each position.
+func Func(bounds image.Rectangle, at func(p image.Point) (int, bool)) Grid {
+	return funcGrid{bounds, at}
+}
+
+type funcGrid struct {
+	bounds image.Rectangle
+	at     func(p image.Point) (int, bool)
+}
+
+func (grid funcGrid) Bounds() image.Rectangle {
+	return grid.bounds
+}
+
+func (grid funcGrid) At(p image.Point) (int, bool) {
+	return grid.at(p)
+}
+
+// A Tile describes how a single position is drawn. The glyph is used for
+// text output and the color for everything else. Tiles without a color are
+// drawn in the glyph's default color in the terminal and are left out of
+// images.
+type Tile struct {
+	Glyph rune
+	Color color.Color
+}
+
+type Style struct {
+	// Maps values to tiles. Values that are not in the palette are drawn as
+	// the character with that code (see Lines).
+	Palette map[int]Tile
+
+	// Used for positions with unknown values.
+	Unknown Tile
+
+	// Tiles for special positions, which are drawn instead of their values.
+	Highlights map[image.Point]Tile
+
+	// Size of a tile in pixels for images. Defaults to 8.
+	Scale int
+
+	// Color of the image behind the tiles. Defaults to black.
+	Background color.Color
+}
+
+// Highlight draws the tile at p instead of its value.
+func (style *Style) Highlight(p image.Point, tile Tile) {
+	if style.Highlights == nil {
+		style.Highlights = make(map[image.Point]Tile)
+	}
+	style.Highlights[p] = tile
+}
+
+func (style *Style) tile(grid Grid, p image.Point) Tile {
+	if tile, ok := style.Highlights[p]; ok {
+		return tile
+	}
+
+	value, ok := grid.At(p)
+	if !ok {
+		return style.Unknown
+	}
+
+	if tile, ok := style.Palette[value]; ok {
+		return tile
+	}
+	return Tile{Glyph: rune(value)}
+}
+
+func (style *Style) scale() int {
+	if style.Scale <= 0 {
+		return 8
+	}
+	return style.Scale
+}
+
+func (style *Style) background() color.Color {
+	if style.Background == nil {
+		return color.Black
+	}
+	return style.Background
+}
+
+func max(x, y int) int {
+	if y > x {
+		return y
+	}
+	return x
+}
@@ -0,0 +1,128 @@
+package render
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write the outputs of the golden tests to testdata instead of checking them")
+
+// A small maze with every kind of tile: palette values, a plain character,
+// an unknown position and a highlight.
+func testGrid() (Grid, Style) {
+	grid := Sparse{
+		{0, 0}: '#', {1, 0}: '#', {2, 0}: '#', {3, 0}: '#',
+		{0, 1}: '#', {1, 1}: '.', {2, 1}: 'k', {3, 1}: '#',
+		{0, 2}: '#', {1, 2}: '.' /* unknown */, {3, 2}: '#',
+		{0, 3}: '#', {1, 3}: '#', {2, 3}: '#', {3, 3}: '#',
+	}
+
+	style := Style{
+		Palette: map[int]Tile{
+			'#': {Glyph: '█', Color: color.RGBA{0x40, 0x40, 0x40, 0xff}},
+			'.': {Glyph: '.', Color: color.RGBA{0xe0, 0xe0, 0xe0, 0xff}},
+		},
+		Unknown: Tile{Glyph: '?'},
+		Scale:   2,
+	}
+	style.Highlight(image.Point{1, 1}, Tile{Glyph: '@', Color: color.RGBA{0xff, 0x00, 0x00, 0xff}})
+
+	return grid, style
+}
+
+func golden(t *testing.T, name string, got []byte) []byte {
+	t.Helper()
+	filename := filepath.Join("testdata", name)
+	if *update {
+		if err := ioutil.WriteFile(filename, got, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return want
+}
+
+func TestGolden(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(io.Writer, Grid, Style) error
+	}{
+		{"maze.txt", Text},
+		{"maze.ans", ANSI},
+		{"maze.svg", SVG},
+	}
+
+	grid, style := testGrid()
+	for _, test := range tests {
+		var buffer bytes.Buffer
+		if err := test.write(&buffer, grid, style); err != nil {
+			t.Fatal(err)
+		}
+		if want := golden(t, test.name, buffer.Bytes()); !bytes.Equal(buffer.Bytes(), want) {
+			t.Errorf("%s: got\n%s\nwant\n%s", test.name, buffer.Bytes(), want)
+		}
+	}
+}
+
+// The PNG is compared pixel by pixel, because the compressed data may change
+// with the version of the encoder.
+func TestGoldenPNG(t *testing.T) {
+	grid, style := testGrid()
+
+	var buffer bytes.Buffer
+	if err := PNG(&buffer, grid, style); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := png.Decode(bytes.NewReader(golden(t, "maze.png", buffer.Bytes())))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := Image(grid, style)
+
+	if got.Bounds() != want.Bounds() {
+		t.Fatalf("got bounds %v, want %v", got.Bounds(), want.Bounds())
+	}
+	for y := got.Bounds().Min.Y; y < got.Bounds().Max.Y; y++ {
+		for x := got.Bounds().Min.X; x < got.Bounds().Max.X; x++ {
+			r1, g1, b1, a1 := got.At(x, y).RGBA()
+			r2, g2, b2, a2 := want.At(x, y).RGBA()
+			if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+				t.Fatalf("pixel (%d, %d): got %v, want %v", x, y, got.At(x, y), want.At(x, y))
+			}
+		}
+	}
+}
+
+func TestSparseBounds(t *testing.T) {
+	grid := Sparse{{-2, 3}: 1, {4, -1}: 1}
+	if got, want := grid.Bounds(), image.Rect(-2, -1, 5, 4); got != want {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := (Sparse{}).Bounds(); !got.Empty() {
+		t.Errorf("got %v for an empty grid", got)
+	}
+}
+
+func TestLines(t *testing.T) {
+	grid := Lines([]string{"ab", "c"})
+
+	var buffer bytes.Buffer
+	if err := Text(&buffer, grid, Style{}); err != nil {
+		t.Fatal(err)
+	}
+	// Shorter lines are padded with spaces.
+	if got, want := buffer.String(), "ab\nc \n"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/color"
+	"io"
+)
+
+// SVG writes the grid as a scalable image with one rectangle per colored
+// tile. Unlike Image, horizontal runs of tiles with the same color are merged
+// into a single rectangle to keep the file small.
+func SVG(w io.Writer, grid Grid, style Style) error {
+	writer := bufio.NewWriter(w)
+	bounds := grid.Bounds()
+	scale := style.scale()
+
+	width, height := bounds.Dx()*scale, bounds.Dy()*scale
+	fmt.Fprintf(writer, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\" shape-rendering=\"crispEdges\">\n", width, height, width, height)
+	fmt.Fprintf(writer, "<rect width=\"%d\" height=\"%d\" fill=\"%s\"/>\n", width, height, hex(style.background()))
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; {
+			tile := style.tile(grid, image.Point{x, y})
+			if tile.Color == nil {
+				x++
+				continue
+			}
+
+			fill := hex(tile.Color)
+			start := x
+			for x++; x < bounds.Max.X; x++ {
+				next := style.tile(grid, image.Point{x, y})
+				if next.Color == nil || hex(next.Color) != fill {
+					break
+				}
+			}
+
+			fmt.Fprintf(writer, "<rect x=\"%d\" y=\"%d\" width=\"%d\" height=\"%d\" fill=\"%s\"/>\n",
+				(start-bounds.Min.X)*scale, (y-bounds.Min.Y)*scale, (x-start)*scale, scale, fill)
+		}
+	}
+
+	fmt.Fprintln(writer, "</svg>")
+	return writer.Flush()
+}
+
+func hex(c color.Color) string {
+	rgba := color.NRGBAModel.Convert(c).(color.NRGBA)
+	if rgba.A != 0xff {
+		return fmt.Sprintf("#%02x%02x%02x%02x", rgba.R, rgba.G, rgba.B, rgba.A)
+	}
+	return fmt.Sprintf("#%02x%02x%02x", rgba.R, rgba.G, rgba.B)
+}
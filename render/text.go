@@ -0,0 +1,45 @@
+package render
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"io"
+)
+
+// Text writes the glyphs of the grid, one line per row.
+func Text(w io.Writer, grid Grid, style Style) error {
+	return text(w, grid, style, false)
+}
+
+// ANSI writes the glyphs of the grid like Text, but colors each glyph using
+// 24-bit ANSI escape sequences.
+func ANSI(w io.Writer, grid Grid, style Style) error {
+	return text(w, grid, style, true)
+}
+
+func text(w io.Writer, grid Grid, style Style, colored bool) error {
+	writer := bufio.NewWriter(w)
+	bounds := grid.Bounds()
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			tile := style.tile(grid, image.Point{x, y})
+
+			glyph := tile.Glyph
+			if glyph == 0 {
+				glyph = ' '
+			}
+
+			if colored && tile.Color != nil {
+				r, g, b, _ := tile.Color.RGBA()
+				fmt.Fprintf(writer, "\x1b[38;2;%d;%d;%dm%c\x1b[0m", r>>8, g>>8, b>>8, glyph)
+			} else {
+				writer.WriteRune(glyph)
+			}
+		}
+		writer.WriteByte('\n')
+	}
+
+	return writer.Flush()
+}
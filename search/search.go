@@ -0,0 +1,168 @@
+// Package search finds shortest paths in graphs that are given by a function
+// returning the neighbors of a node, so that the graph never has to be built
+// explicitly. Nodes can be values of any type that can be used as a map key.
+package search
+
+import "greenlightning.eu/aoc19/priority"
+
+type Node interface{}
+
+// An Edge leads to a neighbor of a node. The cost must not be negative.
+type Edge struct {
+	To   Node
+	Cost int
+}
+
+type Result struct {
+	// The length of the shortest path from any source to each node whose
+	// distance is known. If the search stopped early at a target, nodes
+	// that are further away than the target may be missing.
+	Distances map[Node]int
+
+	// The first node for which the target function returned true, if Found
+	// is true.
+	Target Node
+	Found  bool
+
+	previous map[Node]Node
+}
+
+// Distance returns the length of the shortest path to node.
+func (result *Result) Distance(node Node) (int, bool) {
+	distance, ok := result.Distances[node]
+	return distance, ok
+}
+
+// Path returns the nodes of a shortest path from one of the sources to node,
+// including both ends, or nil if the distance of node is not known.
+func (result *Result) Path(node Node) []Node {
+	if _, ok := result.Distances[node]; !ok {
+		return nil
+	}
+
+	path := []Node{node}
+	for {
+		previous, ok := result.previous[node]
+		if !ok {
+			break
+		}
+		path = append(path, previous)
+		node = previous
+	}
+
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+func newResult() *Result {
+	return &Result{
+		Distances: make(map[Node]int),
+		previous:  make(map[Node]Node),
+	}
+}
+
+// BFS searches a graph in which every edge has a length of one. It starts at
+// all sources at once and stops at the first node for which target returns
+// true. If target is nil, it visits every reachable node.
+func BFS(sources []Node, neighbors func(node Node) []Node, target func(node Node) bool) *Result {
+	result := newResult()
+
+	var open []Node
+	for _, source := range sources {
+		if _, ok := result.Distances[source]; !ok {
+			result.Distances[source] = 0
+			open = append(open, source)
+		}
+	}
+
+	for len(open) != 0 {
+		current := open[0]
+		open = open[1:]
+
+		if target != nil && target(current) {
+			result.Target, result.Found = current, true
+			return result
+		}
+
+		for _, next := range neighbors(current) {
+			if _, ok := result.Distances[next]; !ok {
+				result.Distances[next] = result.Distances[current] + 1
+				result.previous[next] = current
+				open = append(open, next)
+			}
+		}
+	}
+
+	return result
+}
+
+// Dijkstra is like BFS, but for graphs whose edges have different costs.
+func Dijkstra(sources []Node, neighbors func(node Node) []Edge, target func(node Node) bool) *Result {
+	return AStar(sources, neighbors, nil, target)
+}
+
+// AStar is like Dijkstra, but visits the nodes that look closer to a target
+// first. The heuristic estimates the remaining distance from a node to the
+// nearest target. The estimate must be zero at the targets and may decrease
+// by at most the cost of an edge when following it (e.g. the Manhattan
+// distance on a grid), otherwise the result may not be the shortest path. A
+// nil heuristic estimates zero everywhere, which turns AStar into Dijkstra.
+func AStar(sources []Node, neighbors func(node Node) []Edge, heuristic func(node Node) int, target func(node Node) bool) *Result {
+	result := newResult()
+
+	estimate := func(node Node) int {
+		if heuristic == nil {
+			return 0
+		}
+		return heuristic(node)
+	}
+
+	// The open nodes with their tentative distances. The priority is the
+	// estimate of the total length, so that the shortest paths are popped
+	// first.
+	queue := priority.MakeQueue(priority.MinFirst)
+	items := make(map[Node]*priority.Item)
+	tentative := make(map[Node]int)
+
+	for _, source := range sources {
+		if _, ok := items[source]; !ok {
+			items[source] = queue.Push(source, estimate(source))
+			tentative[source] = 0
+		}
+	}
+
+	for !queue.Empty() {
+		current := queue.Pop().Value
+		distance := tentative[current]
+		result.Distances[current] = distance
+
+		if target != nil && target(current) {
+			result.Target, result.Found = current, true
+			return result
+		}
+
+		for _, edge := range neighbors(current) {
+			if _, done := result.Distances[edge.To]; done {
+				continue
+			}
+
+			nextDistance := distance + edge.Cost
+			item, ok := items[edge.To]
+			if ok && nextDistance >= tentative[edge.To] {
+				continue
+			}
+
+			tentative[edge.To] = nextDistance
+			result.previous[edge.To] = current
+			if ok {
+				queue.Update(item, nextDistance+estimate(edge.To))
+			} else {
+				items[edge.To] = queue.Push(edge.To, nextDistance+estimate(edge.To))
+			}
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"image"
+	"reflect"
+	"testing"
+)
+
+// The cost of entering each tile, or a wall.
+var costs = []string{
+	"11111",
+	"19#91",
+	"19#91",
+	"11191",
+}
+
+func cost(p image.Point) (int, bool) {
+	if p.X < 0 || p.Y < 0 || p.Y >= len(costs) || p.X >= len(costs[p.Y]) || costs[p.Y][p.X] == '#' {
+		return 0, false
+	}
+	return int(costs[p.Y][p.X] - '0'), true
+}
+
+func neighbors(node Node) []Node {
+	var result []Node
+	for _, offset := range []image.Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		next := node.(image.Point).Add(offset)
+		if _, ok := cost(next); ok {
+			result = append(result, next)
+		}
+	}
+	return result
+}
+
+func edges(node Node) []Edge {
+	var result []Edge
+	for _, next := range neighbors(node) {
+		c, _ := cost(next.(image.Point))
+		result = append(result, Edge{next, c})
+	}
+	return result
+}
+
+func is(target image.Point) func(node Node) bool {
+	return func(node Node) bool {
+		return node == target
+	}
+}
+
+func TestBFS(t *testing.T) {
+	start, end := image.Point{0, 0}, image.Point{4, 3}
+
+	result := BFS([]Node{start}, neighbors, nil)
+	if len(result.Distances) != 18 {
+		t.Errorf("reached %d nodes, want 18", len(result.Distances))
+	}
+	if d, _ := result.Distance(end); d != 7 {
+		t.Errorf("distance = %d, want 7", d)
+	}
+
+	path := result.Path(end)
+	if len(path) != 8 || path[0] != start || path[7] != end {
+		t.Errorf("path = %v", path)
+	}
+	for i := 1; i < len(path); i++ {
+		d := path[i].(image.Point).Sub(path[i-1].(image.Point))
+		if abs(d.X)+abs(d.Y) != 1 {
+			t.Errorf("path jumps from %v to %v", path[i-1], path[i])
+		}
+	}
+
+	if result.Path(image.Point{2, 1}) != nil {
+		t.Error("found path into a wall")
+	}
+}
+
+func TestBFSEarlyExit(t *testing.T) {
+	result := BFS([]Node{image.Point{0, 0}}, neighbors, is(image.Point{1, 1}))
+	if !result.Found || result.Target != (image.Point{1, 1}) {
+		t.Fatalf("target not found: %v", result.Target)
+	}
+	if _, ok := result.Distance(image.Point{4, 3}); ok {
+		t.Error("search continued after the target")
+	}
+
+	result = BFS([]Node{image.Point{0, 0}}, neighbors, is(image.Point{9, 9}))
+	if result.Found {
+		t.Error("found unreachable target")
+	}
+}
+
+func TestBFSMultipleSources(t *testing.T) {
+	sources := []Node{image.Point{0, 0}, image.Point{4, 3}}
+	result := BFS(sources, neighbors, nil)
+
+	for _, source := range sources {
+		if d, _ := result.Distance(source); d != 0 {
+			t.Errorf("distance of source %v = %d", source, d)
+		}
+		if path := result.Path(source); !reflect.DeepEqual(path, []Node{source}) {
+			t.Errorf("path to source %v = %v", source, path)
+		}
+	}
+	if d, _ := result.Distance(image.Point{4, 0}); d != 3 {
+		t.Errorf("distance to (4, 0) = %d, want 3", d)
+	}
+}
+
+func TestDijkstra(t *testing.T) {
+	start, end := image.Point{0, 0}, image.Point{4, 3}
+
+	// Going around the top avoids the expensive tiles.
+	result := Dijkstra([]Node{start}, edges, is(end))
+	if d, _ := result.Distance(end); d != 7 {
+		t.Errorf("distance = %d, want 7", d)
+	}
+	want := []Node{start, image.Point{1, 0}, image.Point{2, 0}, image.Point{3, 0}, image.Point{4, 0}, image.Point{4, 1}, image.Point{4, 2}, end}
+	if path := result.Path(end); !reflect.DeepEqual(path, want) {
+		t.Errorf("path = %v, want %v", path, want)
+	}
+
+	// Entering (3, 2) is expensive from every side.
+	result = Dijkstra([]Node{start}, edges, nil)
+	if d, _ := result.Distance(image.Point{3, 2}); d != 15 {
+		t.Errorf("distance to (3, 2) = %d, want 15", d)
+	}
+}
+
+func TestAStar(t *testing.T) {
+	start := image.Point{0, 0}
+	complete := Dijkstra([]Node{start}, edges, nil)
+
+	for node, want := range complete.Distances {
+		target := node.(image.Point)
+		heuristic := func(node Node) int {
+			d := target.Sub(node.(image.Point))
+			return abs(d.X) + abs(d.Y)
+		}
+
+		result := AStar([]Node{start}, edges, heuristic, is(target))
+		if d, _ := result.Distance(target); !result.Found || d != want {
+			t.Errorf("distance to %v = %d, want %d", target, d, want)
+		}
+		if len(result.Distances) > len(complete.Distances) {
+			t.Errorf("visited %d nodes on the way to %v", len(result.Distances), target)
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
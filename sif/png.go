@@ -0,0 +1,105 @@
+package sif
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+)
+
+var palette = color.Palette{
+	Black:       color.Black,
+	White:       color.White,
+	Transparent: color.Transparent,
+}
+
+// ToImage converts the pixels of a layer (or a flattened image) into an image
+// with one image pixel per pixel.
+func (img *Image) ToImage(pixels []byte) *image.Paletted {
+	result := image.NewPaletted(image.Rect(0, 0, img.Width, img.Height), palette)
+	copy(result.Pix, pixels)
+	return result
+}
+
+// FromImage converts an image into a layer. Mostly transparent pixels become
+// transparent, all others become black or white depending on their
+// brightness.
+func FromImage(img image.Image) []byte {
+	bounds := img.Bounds()
+	pixels := make([]byte, 0, bounds.Dx()*bounds.Dy())
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.NRGBAModel.Convert(img.At(x, y)).(color.NRGBA)
+			gray := color.GrayModel.Convert(color.RGBA{c.R, c.G, c.B, 0xff}).(color.Gray)
+			switch {
+			case c.A < 0x80:
+				pixels = append(pixels, Transparent)
+			case gray.Y < 0x80:
+				pixels = append(pixels, Black)
+			default:
+				pixels = append(pixels, White)
+			}
+		}
+	}
+
+	return pixels
+}
+
+// FromImages creates an image with one layer per image, from front to back.
+// All images must have the same size.
+func FromImages(images ...image.Image) (*Image, error) {
+	if len(images) == 0 {
+		return nil, fmt.Errorf("sif: no layers")
+	}
+
+	size := images[0].Bounds().Size()
+	result := &Image{Width: size.X, Height: size.Y}
+
+	for index, img := range images {
+		if img.Bounds().Size() != size {
+			return nil, fmt.Errorf("sif: layer %d has size %v, expected %v", index, img.Bounds().Size(), size)
+		}
+		result.Layers = append(result.Layers, FromImage(img))
+	}
+
+	return result, nil
+}
+
+// SavePNG writes the pixels of a layer (or a flattened image) to a PNG file.
+func (img *Image) SavePNG(filename string, pixels []byte) error {
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	if err := png.Encode(file, img.ToImage(pixels)); err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// LoadPNGs reads the PNG files and creates an image with one layer per file
+// (see FromImages).
+func LoadPNGs(filenames ...string) (*Image, error) {
+	var images []image.Image
+	for _, filename := range filenames {
+		file, err := os.Open(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		img, err := png.Decode(file)
+		file.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filename, err)
+		}
+
+		images = append(images, img)
+	}
+
+	return FromImages(images...)
+}
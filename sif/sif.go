@@ -0,0 +1,100 @@
+// Package sif implements the Space Image Format from day 8. An image is
+// stored as a sequence of digits, one per pixel, layer after layer, with the
+// pixels of each layer in row-major order.
+package sif
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// Pixel colors.
+const (
+	Black       = 0
+	White       = 1
+	Transparent = 2
+)
+
+type Image struct {
+	Width, Height int
+
+	// The pixels of each layer, from front to back, with one color per byte.
+	Layers [][]byte
+}
+
+// Decode splits the digits into layers of the given size. Whitespace at the
+// end of the data is ignored.
+func Decode(data []byte, width, height int) (*Image, error) {
+	data = bytes.TrimRight(data, " \t\r\n")
+
+	if width <= 0 || height <= 0 {
+		return nil, fmt.Errorf("sif: invalid size %dx%d", width, height)
+	}
+
+	size := width * height
+	if len(data) == 0 || len(data)%size != 0 {
+		return nil, fmt.Errorf("sif: %d digits do not form layers of %dx%d pixels", len(data), width, height)
+	}
+
+	img := &Image{Width: width, Height: height}
+	for start := 0; start < len(data); start += size {
+		layer := make([]byte, size)
+		for i, char := range data[start : start+size] {
+			if char < '0'+Black || char > '0'+Transparent {
+				return nil, fmt.Errorf("sif: invalid digit %q at offset %d", char, start+i)
+			}
+			layer[i] = char - '0'
+		}
+		img.Layers = append(img.Layers, layer)
+	}
+
+	return img, nil
+}
+
+// Encode returns the digits of the image.
+func (img *Image) Encode() []byte {
+	var data []byte
+	for _, layer := range img.Layers {
+		for _, pixel := range layer {
+			data = append(data, '0'+pixel)
+		}
+	}
+	return data
+}
+
+// Checksum finds the layer with the fewest black pixels and returns the
+// number of white pixels multiplied by the number of transparent pixels in
+// that layer.
+func (img *Image) Checksum() int {
+	var bestHistogram [3]int
+	for index, layer := range img.Layers {
+		var histogram [3]int
+		for _, pixel := range layer {
+			histogram[pixel]++
+		}
+		if index == 0 || histogram[Black] < bestHistogram[Black] {
+			bestHistogram = histogram
+		}
+	}
+	return bestHistogram[White] * bestHistogram[Transparent]
+}
+
+// Flatten stacks the layers and returns the visible color of each pixel,
+// which is the color of the frontmost layer that is not transparent. Pixels
+// that are transparent in all layers stay transparent.
+func (img *Image) Flatten() []byte {
+	result := make([]byte, img.Width*img.Height)
+	for i := range result {
+		result[i] = Transparent
+	}
+
+	for _, layer := range img.Layers {
+		for i, pixel := range layer {
+			if result[i] == Transparent {
+				result[i] = pixel
+			}
+		}
+	}
+
+	return result
+}
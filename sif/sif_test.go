@@ -0,0 +1,111 @@
+package sif
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	// The second layer has the fewest black pixels.
+	img, err := Decode([]byte("001122012212\n"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Checksum(); got != 2*3 {
+		t.Errorf("got %d, want 6", got)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	img, err := Decode([]byte("0222112222120000"), 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := img.Flatten(), []byte{Black, White, White, Black}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestEncode(t *testing.T) {
+	data := []byte("0222112222120000")
+	img, err := Decode(data, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := img.Encode(); !bytes.Equal(got, data) {
+		t.Errorf("got %s, want %s", got, data)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	for _, data := range []string{"", "01201", "0123"} {
+		if _, err := Decode([]byte(data), 2, 2); err == nil {
+			t.Errorf("%q: expected an error", data)
+		}
+	}
+}
+
+func TestPNGRoundTrip(t *testing.T) {
+	// Each layer contains transparent, black and white pixels.
+	data := []byte("210012102201")
+	img, err := Decode(data, 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "sif")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var filenames []string
+	for index, layer := range img.Layers {
+		filename := filepath.Join(dir, fmt.Sprintf("layer%d.png", index))
+		if err := img.SavePNG(filename, layer); err != nil {
+			t.Fatal(err)
+		}
+		filenames = append(filenames, filename)
+	}
+
+	loaded, err := LoadPNGs(filenames...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Width != 3 || loaded.Height != 2 {
+		t.Errorf("got size %dx%d, want 3x2", loaded.Width, loaded.Height)
+	}
+	if got := loaded.Encode(); !bytes.Equal(got, data) {
+		t.Errorf("got %s, want %s", got, data)
+	}
+}
+
+func TestFromImage(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, 4, 1))
+	img.Set(0, 0, color.NRGBA{0xff, 0xff, 0xff, 0x10})
+	img.Set(1, 0, color.NRGBA{0x20, 0x20, 0x20, 0xff})
+	img.Set(2, 0, color.NRGBA{0xe0, 0xe0, 0xe0, 0xff})
+	img.Set(3, 0, color.NRGBA{0xff, 0xff, 0xff, 0xc0})
+
+	if got, want := FromImage(img), []byte{Transparent, Black, White, White}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestFromImagesSizes(t *testing.T) {
+	a := image.NewGray(image.Rect(0, 0, 3, 2))
+	b := image.NewGray(image.Rect(0, 0, 2, 3))
+	if _, err := FromImages(a, b); err == nil {
+		t.Error("expected an error for layers of different sizes")
+	}
+	if _, err := FromImages(); err == nil {
+		t.Error("expected an error without layers")
+	}
+}
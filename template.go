@@ -1,3 +1,6 @@
+//go:build ignore
+// +build ignore
+
 package main
 
 import (
@@ -9,18 +12,17 @@ import (
 	"strings"
 )
 
-func main() {
-	lines := readLines("input.txt")
+func Solve(filename string) (partOne, partTwo string) {
+	lines := readLines(filename)
 
 	{
-		fmt.Println("--- Part One ---")
-		fmt.Println(len(lines))
+		partOne = fmt.Sprint(len(lines))
 	}
 
 	{
-		fmt.Println("--- Part Two ---")
-		fmt.Println()
 	}
+
+	return partOne, partTwo
 }
 
 func readLines(filename string) []string {